@@ -0,0 +1,207 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	kindConstants = map[errors.Kind]string{
+		errors.KindInternal:         "KindInternal",
+		errors.KindInvalidArgument:  "KindInvalidArgument",
+		errors.KindNotFound:         "KindNotFound",
+		errors.KindConflict:         "KindConflict",
+		errors.KindPermissionDenied: "KindPermissionDenied",
+		errors.KindUnauthenticated:  "KindUnauthenticated",
+		errors.KindRateLimited:      "KindRateLimited",
+		errors.KindUnavailable:      "KindUnavailable",
+		errors.KindTimeout:          "KindTimeout",
+		errors.KindCanceled:         "KindCanceled",
+	}
+	severityConstants = map[errors.LogSeverity]string{
+		errors.ERROR: "ERROR",
+		errors.WARN:  "WARN",
+		errors.INFO:  "INFO",
+		errors.DEBUG: "DEBUG",
+	}
+)
+
+type catalog struct {
+	Errors []errorSpec `yaml:"errors"`
+}
+
+type errorSpec struct {
+	Code       string             `yaml:"code"`
+	Name       string             `yaml:"name"`
+	Message    string             `yaml:"message"`
+	Kind       errors.Kind        `yaml:"kind"`
+	HTTPStatus int                `yaml:"http_status"`
+	Severity   errors.LogSeverity `yaml:"severity"`
+	Retryable  bool               `yaml:"retryable"`
+	DocsURL    string             `yaml:"docs_url"`
+	Owner      string             `yaml:"owner"`
+}
+
+// KindConstant returns name of the errors package constant of the kind.
+func (s errorSpec) KindConstant() string {
+	return kindConstants[s.Kind]
+}
+
+// SeverityConstant returns name of the errors package constant of the severity.
+func (s errorSpec) SeverityConstant() string {
+	return severityConstants[s.Severity]
+}
+
+// Status returns the HTTP status of the error.
+func (s errorSpec) Status() int {
+	if s.HTTPStatus != 0 {
+		return s.HTTPStatus
+	}
+	return errors.KindHTTPStatus(s.Kind)
+}
+
+func parseCatalog(data []byte) (catalog, error) {
+	var c catalog
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return c, err
+	}
+
+	codes := map[string]bool{}
+	names := map[string]bool{}
+	for i := range c.Errors {
+		spec := &c.Errors[i]
+		if spec.Code == "" {
+			return c, fmt.Errorf("error #%d: code is required", i+1)
+		}
+		if codes[spec.Code] {
+			return c, fmt.Errorf("error %s: duplicated code", spec.Code)
+		}
+		codes[spec.Code] = true
+
+		if spec.Name == "" {
+			spec.Name = nameFromCode(spec.Code)
+		}
+		if !token.IsIdentifier(spec.Name) || !token.IsExported(spec.Name) {
+			return c, fmt.Errorf("error %s: invalid name %q", spec.Code, spec.Name)
+		}
+		if names[spec.Name] {
+			return c, fmt.Errorf("error %s: duplicated name %q", spec.Code, spec.Name)
+		}
+		names[spec.Name] = true
+
+		if spec.Message == "" {
+			spec.Message = spec.Code
+		}
+		if spec.Kind == "" {
+			spec.Kind = errors.KindInternal
+		}
+		if _, ok := kindConstants[spec.Kind]; !ok {
+			return c, fmt.Errorf("error %s: unknown kind %q", spec.Code, spec.Kind)
+		}
+		if spec.Severity == "" {
+			spec.Severity = errors.ERROR
+		}
+		if _, ok := severityConstants[spec.Severity]; !ok {
+			return c, fmt.Errorf("error %s: unknown severity %q", spec.Code, spec.Severity)
+		}
+	}
+
+	return c, nil
+}
+
+// nameFromCode converts the code to CamelCase, e.g. PROJECT_NOT_FOUND to ProjectNotFound.
+func nameFromCode(code string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(code, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(strings.ToLower(string(runes[1:])))
+	}
+	return b.String()
+}
+
+var codeTemplate = template.Must(template.New("code").Parse(`// Code generated by ncerrgen from {{.Source}}. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/nordcloud/ncerrors/errors"
+{{range .Errors}}
+// Code{{.Name}} is the code of {{printf "%q" .Message}} errors.
+var Code{{.Name}} = errors.RegisterCode(errors.CodeInfo{
+	Code: {{printf "%q" .Code}},
+	Message: {{printf "%q" .Message}},
+	Kind: errors.{{.KindConstant}},
+	{{- if .HTTPStatus}}
+	HTTPStatus: {{.HTTPStatus}},
+	{{- end}}
+	Severity: errors.{{.SeverityConstant}},
+	{{- if .Retryable}}
+	Retryable: true,
+	{{- end}}
+	{{- if .DocsURL}}
+	DocsURL: {{printf "%q" .DocsURL}},
+	{{- end}}
+	{{- if .Owner}}
+	Owner: {{printf "%q" .Owner}},
+	{{- end}}
+})
+
+// Err{{.Name}} is the sentinel of {{.Code}} errors, use errors.Is to match them.
+var Err{{.Name}} = errors.NewCode(Code{{.Name}})
+
+// New{{.Name}} returns {{.Code}} error.
+func New{{.Name}}(fields ...errors.Fields) error {
+	return errors.NewCodeDepth(1, Code{{.Name}}, fields...)
+}
+
+// Wrap{{.Name}} wraps err with {{.Code}} error. It returns nil for nil error.
+func Wrap{{.Name}}(err error, fields ...errors.Fields) error {
+	return errors.WrapCodeDepth(1, err, Code{{.Name}}, fields...)
+}
+{{end}}`))
+
+func generateCode(c catalog, pkg, source string) ([]byte, error) {
+	var buf bytes.Buffer
+	err := codeTemplate.Execute(&buf, struct {
+		Source  string
+		Package string
+		Errors  []errorSpec
+	}{source, pkg, c.Errors})
+	if err != nil {
+		return nil, err
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func generateDoc(c catalog) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Error reference\n\n")
+	buf.WriteString("| Code | Message | Kind | HTTP status | Severity | Retryable | Owner |\n")
+	buf.WriteString("|------|---------|------|-------------|----------|-----------|-------|\n")
+	for _, spec := range c.Errors {
+		code := "`" + spec.Code + "`"
+		if spec.DocsURL != "" {
+			code = "[" + code + "](" + spec.DocsURL + ")"
+		}
+		retryable := "no"
+		if spec.Retryable {
+			retryable = "yes"
+		}
+		fmt.Fprintf(&buf, "| %s | %s | %s | %d | %s | %s | %s |\n",
+			code, strings.ReplaceAll(spec.Message, "|", `\|`), spec.Kind, spec.Status(), spec.Severity, retryable, spec.Owner)
+	}
+	return buf.Bytes()
+}
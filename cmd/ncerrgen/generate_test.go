@@ -0,0 +1,56 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGenerate_Example checks that the generated example package is up to date.
+func TestGenerate_Example(t *testing.T) {
+	data, err := os.ReadFile("internal/example/errors.yaml")
+	assert.NoError(t, err)
+	c, err := parseCatalog(data)
+	assert.NoError(t, err)
+
+	code, err := generateCode(c, "example", "errors.yaml")
+	assert.NoError(t, err)
+	expectedCode, err := os.ReadFile("internal/example/errors_gen.go")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expectedCode), string(code))
+
+	expectedDoc, err := os.ReadFile("internal/example/ERRORS.md")
+	assert.NoError(t, err)
+	assert.Equal(t, string(expectedDoc), string(generateDoc(c)))
+}
+
+func TestParseCatalog_Errors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		yaml string
+		err  string
+	}{
+		"no code":            {"errors: [{message: m}]", "error #1: code is required"},
+		"duplicated code":    {"errors: [{code: A}, {code: A}]", "error A: duplicated code"},
+		"duplicated name":    {"errors: [{code: A_B}, {code: A-B}]", `error A-B: duplicated name "AB"`},
+		"invalid name":       {"errors: [{code: A, name: a}]", `error A: invalid name "a"`},
+		"unknown kind":       {"errors: [{code: A, kind: weird}]", `error A: unknown kind "weird"`},
+		"unknown severity":   {"errors: [{code: A, severity: fatal}]", `error A: unknown severity "fatal"`},
+		"invalid yaml":       {"errors: {", "yaml: line 1: did not find expected node content"},
+		"code starts with 1": {"errors: [{code: 1A}]", `error 1A: invalid name "1a"`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseCatalog([]byte(tc.yaml))
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestNameFromCode(t *testing.T) {
+	assert.Equal(t, "ProjectNotFound", nameFromCode("PROJECT_NOT_FOUND"))
+	assert.Equal(t, "AwsS3AccessDenied", nameFromCode("aws.s3-access_denied"))
+	assert.Equal(t, "ÉtatInvalide", nameFromCode("état_INVALIDE"))
+	assert.Equal(t, "ÄäÖ", nameFromCode("ää-ö"))
+}
@@ -0,0 +1,72 @@
+// Code generated by ncerrgen from errors.yaml. DO NOT EDIT.
+
+package example
+
+import "github.com/nordcloud/ncerrors/errors"
+
+// CodeProjectNotFound is the code of "project not found" errors.
+var CodeProjectNotFound = errors.RegisterCode(errors.CodeInfo{
+	Code:     "PROJECT_NOT_FOUND",
+	Message:  "project not found",
+	Kind:     errors.KindNotFound,
+	Severity: errors.WARN,
+	DocsURL:  "https://docs.example.com/errors/PROJECT_NOT_FOUND",
+	Owner:    "team-projects",
+})
+
+// ErrProjectNotFound is the sentinel of PROJECT_NOT_FOUND errors, use errors.Is to match them.
+var ErrProjectNotFound = errors.NewCode(CodeProjectNotFound)
+
+// NewProjectNotFound returns PROJECT_NOT_FOUND error.
+func NewProjectNotFound(fields ...errors.Fields) error {
+	return errors.NewCodeDepth(1, CodeProjectNotFound, fields...)
+}
+
+// WrapProjectNotFound wraps err with PROJECT_NOT_FOUND error. It returns nil for nil error.
+func WrapProjectNotFound(err error, fields ...errors.Fields) error {
+	return errors.WrapCodeDepth(1, err, CodeProjectNotFound, fields...)
+}
+
+// CodeDependencyDown is the code of "dependency unavailable" errors.
+var CodeDependencyDown = errors.RegisterCode(errors.CodeInfo{
+	Code:       "DEPENDENCY_UNAVAILABLE",
+	Message:    "dependency unavailable",
+	Kind:       errors.KindUnavailable,
+	HTTPStatus: 502,
+	Severity:   errors.ERROR,
+	Retryable:  true,
+})
+
+// ErrDependencyDown is the sentinel of DEPENDENCY_UNAVAILABLE errors, use errors.Is to match them.
+var ErrDependencyDown = errors.NewCode(CodeDependencyDown)
+
+// NewDependencyDown returns DEPENDENCY_UNAVAILABLE error.
+func NewDependencyDown(fields ...errors.Fields) error {
+	return errors.NewCodeDepth(1, CodeDependencyDown, fields...)
+}
+
+// WrapDependencyDown wraps err with DEPENDENCY_UNAVAILABLE error. It returns nil for nil error.
+func WrapDependencyDown(err error, fields ...errors.Fields) error {
+	return errors.WrapCodeDepth(1, err, CodeDependencyDown, fields...)
+}
+
+// CodeInternal is the code of "internal error" errors.
+var CodeInternal = errors.RegisterCode(errors.CodeInfo{
+	Code:     "INTERNAL",
+	Message:  "internal error",
+	Kind:     errors.KindInternal,
+	Severity: errors.ERROR,
+})
+
+// ErrInternal is the sentinel of INTERNAL errors, use errors.Is to match them.
+var ErrInternal = errors.NewCode(CodeInternal)
+
+// NewInternal returns INTERNAL error.
+func NewInternal(fields ...errors.Fields) error {
+	return errors.NewCodeDepth(1, CodeInternal, fields...)
+}
+
+// WrapInternal wraps err with INTERNAL error. It returns nil for nil error.
+func WrapInternal(err error, fields ...errors.Fields) error {
+	return errors.WrapCodeDepth(1, err, CodeInternal, fields...)
+}
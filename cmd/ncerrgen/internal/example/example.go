@@ -0,0 +1,6 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package example contains errors generated by ncerrgen from errors.yaml. It's used to test the generator.
+package example
+
+//go:generate go run github.com/nordcloud/ncerrors/cmd/ncerrgen -in errors.yaml -out errors_gen.go -doc ERRORS.md
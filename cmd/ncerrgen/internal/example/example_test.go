@@ -0,0 +1,36 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package example
+
+import (
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratedErrors(t *testing.T) {
+	err := NewProjectNotFound(errors.Fields{"project_id": "p1"})
+
+	assert.Equal(t, "project not found", err.Error())
+	assert.True(t, errors.Is(err, ErrProjectNotFound))
+	assert.False(t, errors.Is(err, ErrDependencyDown))
+	assert.Equal(t, CodeProjectNotFound, errors.GetCode(err))
+	assert.Equal(t, errors.KindNotFound, errors.GetKind(err))
+	assert.Equal(t, errors.WARN, errors.GetErrorSeverity(err))
+	assert.Equal(t, http.StatusNotFound, errors.HTTPStatus(err))
+	assert.Equal(t, "TestGeneratedErrors", err.(errors.NCError).Causes[0].FuncName)
+	assert.Equal(t, "team-projects", errors.GetOwner(err))
+	assert.Equal(t, "https://docs.example.com/errors/PROJECT_NOT_FOUND", errors.GetDocsURL(err))
+
+	wrapped := WrapDependencyDown(stderrors.New("connection refused"))
+	assert.Equal(t, "dependency unavailable: connection refused", wrapped.Error())
+	assert.True(t, errors.Is(wrapped, ErrDependencyDown))
+	assert.True(t, errors.IsRetryable(wrapped))
+	assert.Equal(t, http.StatusBadGateway, errors.HTTPStatus(wrapped))
+	assert.Equal(t, "TestGeneratedErrors", wrapped.(errors.NCError).Causes[0].FuncName)
+	assert.Contains(t, wrapped.(errors.NCError).Stack[1], "example_test.go(TestGeneratedErrors)")
+	assert.Nil(t, WrapInternal(nil))
+}
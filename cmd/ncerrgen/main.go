@@ -0,0 +1,76 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Command ncerrgen generates typed error constructors and sentinels from the error catalog declared in a YAML file.
+//
+// The catalog file lists the errors:
+//
+//	errors:
+//	  - code: PROJECT_NOT_FOUND
+//	    message: project not found
+//	    kind: not_found            # errors.Kind, defaults to internal
+//	    http_status: 404           # optional, derived from the kind by default
+//	    severity: warning          # error (default), warning, info or debug
+//	    retryable: false
+//	    docs_url: https://docs.example.com/errors/PROJECT_NOT_FOUND
+//	    owner: team-projects       # team owning the error
+//
+// For every error the code is registered with errors.RegisterCode and the following are generated (the name is
+// derived from the code unless set with `name`):
+//
+//	var CodeProjectNotFound = errors.RegisterCode(...)
+//	var ErrProjectNotFound = errors.NewCode(CodeProjectNotFound) // sentinel for errors.Is
+//	func NewProjectNotFound(fields ...errors.Fields) error
+//	func WrapProjectNotFound(err error, fields ...errors.Fields) error
+//
+// Optionally a Markdown reference page of the errors is written too. Use it with go generate:
+//
+//	//go:generate go run github.com/nordcloud/ncerrors/cmd/ncerrgen -in errors.yaml -out errors_gen.go -doc ERRORS.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	in := flag.String("in", "", "path of the YAML error catalog (required)")
+	out := flag.String("out", "errors_gen.go", "path of the generated Go file")
+	pkg := flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of the generated Go file (defaults to $GOPACKAGE)")
+	doc := flag.String("doc", "", "path of the generated Markdown reference page (optional)")
+	flag.Parse()
+
+	if err := run(*in, *out, *pkg, *doc); err != nil {
+		fmt.Fprintf(os.Stderr, "ncerrgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(in, out, pkg, doc string) error {
+	if in == "" || pkg == "" {
+		return fmt.Errorf("both -in and -pkg (or $GOPACKAGE) must be set")
+	}
+
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	catalog, err := parseCatalog(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", in, err)
+	}
+
+	code, err := generateCode(catalog, pkg, filepath.Base(in))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(out, code, 0o644); err != nil {
+		return err
+	}
+
+	if doc != "" {
+		return os.WriteFile(doc, generateDoc(catalog), 0o644)
+	}
+	return nil
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/redshift"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/pkg/errors"
 )
 
 const (
@@ -22,18 +21,7 @@ const (
 
 // GetAWSErrorCode returns the underlying AWS error code from the error.
 func GetAWSErrorCode(err error) string {
-	nativeError := errors.Cause(err)
-	if nativeError == nil {
-		return ""
-	}
-	if ncError, ok := nativeError.(NCError); ok {
-		nativeError = errors.Cause(ncError.RootError)
-	}
-	if nativeError == nil {
-		return ""
-	}
-
-	if awsError, ok := nativeError.(awserr.Error); ok {
+	if awsError, ok := AsType[awserr.Error](err); ok {
 		return awsError.Code()
 	}
 
@@ -2,6 +2,7 @@
 package errors
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -39,3 +40,25 @@ func TestGetAWSErrorCode_MultilevelContext(t *testing.T) {
 	errCode := GetAWSErrorCode(cause)
 	assert.Equal(t, "code1", errCode)
 }
+
+func TestGetAWSErrorCode_Pointer(t *testing.T) {
+	cause := WithContext(awserr.New("code1", "aws error", nil), "context1", nil).(NCError)
+	assert.Equal(t, "code1", GetAWSErrorCode(&cause))
+	assert.Equal(t, "code1", GetAWSErrorCode(fmt.Errorf("std wrap: %w", &cause)))
+}
+
+func TestAsNC_NilPointer(t *testing.T) {
+	var nilErr *NCError
+	err := error(nilErr)
+
+	_, ok := AsNC(err)
+	assert.False(t, ok)
+	assert.Equal(t, ERROR, GetErrorSeverity(err))
+	assert.Equal(t, err, GetRootError(err))
+	assert.Equal(t, "", GetAWSErrorCode(err))
+	assert.Equal(t, ERROR, GetLogDetails(err).Severity)
+	_, ok = AsNC(fmt.Errorf("wrapped: %w", err))
+	assert.False(t, ok)
+	_, ok = AsType[awserr.Error](Join(err, awserr.New("code", "message", nil)))
+	assert.True(t, ok)
+}
@@ -0,0 +1,172 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"net/http"
+	"sync"
+)
+
+// StatusClientClosedRequest is the HTTP status of KindCanceled errors (as used by nginx).
+const StatusClientClosedRequest = 499
+
+var (
+	codesMu sync.RWMutex
+	codes   = map[string]CodeInfo{}
+
+	kindHTTPStatuses = map[Kind]int{
+		KindInternal:         http.StatusInternalServerError,
+		KindInvalidArgument:  http.StatusBadRequest,
+		KindNotFound:         http.StatusNotFound,
+		KindConflict:         http.StatusConflict,
+		KindPermissionDenied: http.StatusForbidden,
+		KindUnauthenticated:  http.StatusUnauthorized,
+		KindRateLimited:      http.StatusTooManyRequests,
+		KindUnavailable:      http.StatusServiceUnavailable,
+		KindTimeout:          http.StatusGatewayTimeout,
+		KindCanceled:         StatusClientClosedRequest,
+	}
+)
+
+// CodeInfo describes the error code in the registry, see RegisterCode.
+type CodeInfo struct {
+	Code string
+	// Message is the default message of the errors with the code.
+	Message string
+	Kind    Kind
+	// HTTPStatus overrides the HTTP status derived from the Kind.
+	HTTPStatus int
+	// Severity defaults to ERROR.
+	Severity  LogSeverity
+	Retryable bool
+	DocsURL   string
+	// Owner is the team owning the errors with the code.
+	Owner string
+}
+
+// RegisterCode registers the error code, so errors with the code can be created with NewCode and WrapCode. It returns
+// the code, so it can be used in package variable declarations. Registering the same code again overrides it.
+func RegisterCode(info CodeInfo) string {
+	codesMu.Lock()
+	defer codesMu.Unlock()
+
+	codes[info.Code] = info
+	return info.Code
+}
+
+// LookupCode returns the registered CodeInfo of the code.
+func LookupCode(code string) (CodeInfo, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+
+	info, ok := codes[code]
+	return info, ok
+}
+
+// NewCode returns new error with the code. The message, kind and severity are taken from the registered CodeInfo.
+// Unregistered codes are used as the message.
+func NewCode(code string, fields ...Fields) error {
+	return newCode(1, nil, code, fields)
+}
+
+// NewCodeDepth is NewCode to be used in the wrapper functions, e.g. the generated constructors. The depth is the
+// number of the wrapper functions which are skipped when recording the caller.
+func NewCodeDepth(depth int, code string, fields ...Fields) error {
+	return newCode(1+depth, nil, code, fields)
+}
+
+// WrapCode wraps err with the error of the code, see NewCode. It returns nil for nil error.
+func WrapCode(err error, code string, fields ...Fields) error {
+	if err == nil {
+		return nil
+	}
+	return newCode(1, err, code, fields)
+}
+
+// WrapCodeDepth is WrapCode to be used in the wrapper functions, see NewCodeDepth.
+func WrapCodeDepth(depth int, err error, code string, fields ...Fields) error {
+	if err == nil {
+		return nil
+	}
+	return newCode(1+depth, err, code, fields)
+}
+
+// IsRetryable tells if the operation failed with the error may be retried. It uses the registered CodeInfo of the
+// error code, otherwise KindUnavailable, KindTimeout and KindRateLimited errors are retryable.
+func IsRetryable(err error) bool {
+	if info, ok := LookupCode(GetCode(err)); ok {
+		return info.Retryable
+	}
+
+	switch GetKind(err) {
+	case KindUnavailable, KindTimeout, KindRateLimited:
+		return true
+	default:
+		return false
+	}
+}
+
+// HTTPStatus returns the HTTP status of the error. It uses the registered CodeInfo of the error code, otherwise the
+// status is derived from the error kind.
+func HTTPStatus(err error) int {
+	if info, ok := LookupCode(GetCode(err)); ok && info.HTTPStatus != 0 {
+		return info.HTTPStatus
+	}
+
+	return KindHTTPStatus(GetKind(err))
+}
+
+// KindHTTPStatus returns the HTTP status of the kind. Unknown kinds are mapped to 500 Internal Server Error.
+func KindHTTPStatus(kind Kind) int {
+	if status, ok := kindHTTPStatuses[kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// newCode creates the coded error. The skip is the number of the frames between newCode and the caller to be
+// recorded.
+func newCode(skip int, err error, code string, fields []Fields) error {
+	fileName, funcName, lineNumber := getRuntimeContextSkip(skip)
+	newCause := Cause{
+		Message:  code,
+		Fields:   mergeFields(fields...),
+		FuncName: funcName,
+		FileName: fileName,
+		Line:     lineNumber,
+		Severity: ERROR,
+		Code:     code,
+	}
+	if info, ok := LookupCode(code); ok {
+		if info.Message != "" {
+			newCause.Message = info.Message
+		}
+		if info.Severity != "" {
+			newCause.Severity = info.Severity
+		}
+		newCause.Kind = info.Kind
+	}
+
+	if err != nil {
+		if ncError, wrapperCauses, ok := unwrapNCError(err); ok {
+			causes := append([]Cause{newCause}, wrapperCauses...)
+			ncError.Causes = append(causes, ncError.Causes...)
+			return ncError
+		}
+	}
+
+	stack, rawStack := getStackTracesSkip(skip)
+	if err == nil {
+		return NCError{
+			Causes:   []Cause{newCause},
+			Stack:    stack,
+			RawStack: rawStack,
+		}
+	}
+	return NCError{
+		Causes:    []Cause{newCause, {Message: err.Error()}},
+		Stack:     stack,
+		RawStack:  rawStack,
+		RootError: err,
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testCodeNotFound = RegisterCode(CodeInfo{
+		Code:     "TEST_NOT_FOUND",
+		Message:  "test resource not found",
+		Kind:     KindNotFound,
+		Severity: WARN,
+		DocsURL:  "https://docs.example.com/errors/TEST_NOT_FOUND",
+	})
+	testCodeThrottled = RegisterCode(CodeInfo{
+		Code:       "TEST_THROTTLED",
+		Message:    "test throttled",
+		Kind:       KindRateLimited,
+		HTTPStatus: http.StatusServiceUnavailable,
+		Retryable:  false,
+	})
+
+	errTestNotFound = NewCode(testCodeNotFound)
+)
+
+func newTestNotFound(fields ...Fields) error {
+	return NewCodeDepth(1, testCodeNotFound, fields...)
+}
+
+func TestNewCode(t *testing.T) {
+	err := NewCode(testCodeNotFound, Fields{"id": 1}).(NCError)
+
+	assert.Equal(t, "test resource not found", err.Error())
+	assert.Equal(t, Cause{
+		Message:  "test resource not found",
+		Fields:   Fields{"id": 1},
+		FuncName: "TestNewCode",
+		FileName: "github.com/nordcloud/ncerrors/errors/code_test.go",
+		Line:     39,
+		Severity: WARN,
+		Code:     testCodeNotFound,
+		Kind:     KindNotFound,
+	}, err.Causes[0])
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/code_test.go(TestNewCode):39", err.Stack[1])
+
+	unregistered := NewCode("UNREGISTERED")
+	assert.Equal(t, "UNREGISTERED", unregistered.Error())
+	assert.Equal(t, ERROR, GetErrorSeverity(unregistered))
+}
+
+func TestNewCodeDepth(t *testing.T) {
+	err := newTestNotFound().(NCError)
+
+	assert.Equal(t, "TestNewCodeDepth", err.Causes[0].FuncName)
+	assert.Equal(t, 60, err.Causes[0].Line)
+	assert.Equal(t, []string{
+		"github.com/nordcloud/ncerrors/errors/code_test.go(newTestNotFound):35",
+		"github.com/nordcloud/ncerrors/errors/code_test.go(TestNewCodeDepth):60",
+	}, err.Stack[:2])
+}
+
+func TestWrapCode(t *testing.T) {
+	assert.Nil(t, WrapCode(nil, testCodeNotFound))
+
+	rootErr := errors.New("root")
+	err := WrapCode(rootErr, testCodeNotFound, Fields{"id": 1})
+	assert.Equal(t, "test resource not found: root", err.Error())
+	assert.Equal(t, rootErr, GetRootError(err))
+	assert.Equal(t, "TestWrapCode", err.(NCError).Causes[0].FuncName)
+
+	wrapped := WrapCodeDepth(0, fmt.Errorf("std wrap: %w", err), testCodeThrottled).(NCError)
+	assert.Equal(t, "test throttled: std wrap: test resource not found: root", wrapped.Error())
+	assert.Equal(t, err.(NCError).Stack, wrapped.Stack)
+	assert.Equal(t, testCodeThrottled, GetCode(wrapped))
+}
+
+func TestIs_Code(t *testing.T) {
+	assert.True(t, Is(newTestNotFound(), errTestNotFound))
+	assert.True(t, Is(Wrap(newTestNotFound(), "level2"), errTestNotFound))
+	assert.True(t, Is(WithCode(New("custom message"), testCodeNotFound), errTestNotFound))
+	assert.False(t, Is(NewCode(testCodeThrottled), errTestNotFound))
+}
+
+func TestIsRetryable(t *testing.T) {
+	assert.False(t, IsRetryable(NewCode(testCodeThrottled)))
+	assert.False(t, IsRetryable(NewCode(testCodeNotFound)))
+	assert.True(t, IsRetryable(WithKind(New("error"), KindUnavailable)))
+	assert.True(t, IsRetryable(Wrap(context.DeadlineExceeded, "call")))
+	assert.False(t, IsRetryable(errors.New("std")))
+}
+
+func TestHTTPStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, HTTPStatus(NewCode(testCodeNotFound)))
+	assert.Equal(t, http.StatusServiceUnavailable, HTTPStatus(NewCode(testCodeThrottled)))
+	assert.Equal(t, http.StatusForbidden, HTTPStatus(WithKind(New("error"), KindPermissionDenied)))
+	assert.Equal(t, http.StatusInternalServerError, HTTPStatus(errors.New("std")))
+	assert.Equal(t, http.StatusInternalServerError, KindHTTPStatus(Kind("unknown")))
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+type fieldsCtxKey struct{}
+
+// ContextFieldsFunc returns the fields carried by ctx other than the ones attached with ContextWithFields, e.g. the
+// IDs of the active trace. See AddContextFields.
+type ContextFieldsFunc func(ctx context.Context) Fields
+
+type contextFieldsEntry struct {
+	fn ContextFieldsFunc
+}
+
+var (
+	contextFieldsMu sync.RWMutex
+	contextFields   []*contextFieldsEntry
+)
+
+// AddContextFields registers fn adding its fields to FieldsFromContext and returns the function removing it. The
+// fields of the later registered functions take precedence.
+func AddContextFields(fn ContextFieldsFunc) (remove func()) {
+	entry := &contextFieldsEntry{fn: fn}
+
+	contextFieldsMu.Lock()
+	defer contextFieldsMu.Unlock()
+	contextFields = append(contextFields, entry)
+
+	return func() {
+		contextFieldsMu.Lock()
+		defer contextFieldsMu.Unlock()
+		for i, e := range contextFields {
+			if e == entry {
+				contextFields = append(contextFields[:i:i], contextFields[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// ContextWithFields returns copy of ctx with fields attached. Fields already attached to ctx are extended, so the
+// request-scoped fields (request ID, tenant, user, etc.) can be added at different layers.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	ctxFields, _ := ctx.Value(fieldsCtxKey{}).(Fields)
+	return context.WithValue(ctx, fieldsCtxKey{}, ctxFields.Extend(fields))
+}
+
+// FieldsFromContext returns fields attached to ctx with ContextWithFields extended with the fields of the functions
+// registered with AddContextFields.
+func FieldsFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(fieldsCtxKey{}).(Fields)
+	if len(fields) > 0 {
+		// Fields stored in ctx are shared, so the copy is returned.
+		fields = fields.copy()
+	}
+
+	contextFieldsMu.RLock()
+	entries := contextFields
+	contextFieldsMu.RUnlock()
+
+	for _, entry := range entries {
+		if extFields := entry.fn(ctx); len(extFields) > 0 {
+			fields = fields.Extend(extFields)
+		}
+	}
+
+	return fields
+}
+
+// NewCtx error with context. Fields attached to ctx are merged into the error fields, the explicitly passed fields
+// take precedence.
+func NewCtx(ctx context.Context, message string, fields ...Fields) error {
+	return newError(1, message, ERROR, append([]Fields{FieldsFromContext(ctx)}, fields...))
+}
+
+// WrapCtx wraps err with message and error context and checks for nil error. Fields attached to ctx are merged into
+// the error fields, the explicitly passed fields take precedence.
+func WrapCtx(ctx context.Context, err error, message string, fields ...Fields) error {
+	if err == nil {
+		return nil
+	}
+
+	return withContext(1, err, message, ERROR, append([]Fields{FieldsFromContext(ctx)}, fields...))
+}
@@ -0,0 +1,73 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWithFields(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "req1", "tenant": "t1"})
+	ctx = ContextWithFields(ctx, Fields{"user": "u1", "tenant": "t2"})
+
+	assert.Equal(t, Fields{"request_id": "req1", "tenant": "t2", "user": "u1"}, FieldsFromContext(ctx))
+	assert.Nil(t, FieldsFromContext(context.Background()))
+}
+
+func TestNewCtx(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "req1", "tenant": "t1"})
+
+	err := NewCtx(ctx, "error1", Fields{"tenant": "t2"}).(NCError)
+
+	assert.Equal(t, "error1", err.Error())
+	assert.Equal(t, Fields{"request_id": "req1", "tenant": "t2"}, err.Causes[0].Fields)
+	assert.Equal(t, "TestNewCtx", err.Causes[0].FuncName)
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/context_test.go", err.Causes[0].FileName)
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/context_test.go(TestNewCtx):24", err.Stack[1])
+}
+
+func TestWrapCtx(t *testing.T) {
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "req1"})
+
+	assert.Nil(t, WrapCtx(ctx, nil, "level1"))
+
+	rootErr := errors.New("root")
+	level1 := WrapCtx(ctx, rootErr, "level1").(NCError)
+	assert.Equal(t, "level1: root", level1.Error())
+	assert.Equal(t, rootErr, level1.RootError)
+	assert.Equal(t, Fields{"request_id": "req1"}, level1.Causes[0].Fields)
+	assert.Equal(t, "TestWrapCtx", level1.Causes[0].FuncName)
+
+	level2 := WrapCtx(ContextWithFields(ctx, Fields{"user": "u1"}), level1, "level2").(NCError)
+	assert.Equal(t, "level2: level1: root", level2.Error())
+	assert.Equal(t, level1.Stack, level2.Stack)
+	assert.Equal(t, Fields{"request_id": "req1", "user": "u1"}, level2.GetMergedFields())
+
+	// Fields stored in the context remain intact.
+	level2.Causes[0].Fields["user"] = "u2"
+	assert.Equal(t, Fields{"request_id": "req1"}, FieldsFromContext(ctx))
+}
+
+type tenantCtxKey struct{}
+
+func TestAddContextFields(t *testing.T) {
+	remove := AddContextFields(func(ctx context.Context) Fields {
+		if tenant, ok := ctx.Value(tenantCtxKey{}).(string); ok {
+			return Fields{"tenant": tenant}
+		}
+		return nil
+	})
+	ctx := ContextWithFields(context.Background(), Fields{"request_id": "req1", "tenant": "t1"})
+
+	assert.Equal(t, Fields{"request_id": "req1", "tenant": "t1"}, FieldsFromContext(ctx))
+	assert.Equal(t, Fields{"request_id": "req1", "tenant": "t2"},
+		FieldsFromContext(context.WithValue(ctx, tenantCtxKey{}, "t2")))
+
+	remove()
+	assert.Equal(t, Fields{"request_id": "req1", "tenant": "t1"},
+		FieldsFromContext(context.WithValue(ctx, tenantCtxKey{}, "t2")))
+}
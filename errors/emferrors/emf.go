@@ -0,0 +1,206 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package emferrors provides CloudWatch Embedded Metric Format (EMF) output for NCError. The EMF log lines written to
+// the standard output of AWS Lambda are turned into CloudWatch metrics without an agent.
+package emferrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMetricName is the name of the error count metric.
+	DefaultMetricName = "Errors"
+	// DimensionCode is the dimension holding the error code (see errors.GetCode).
+	DimensionCode = "code"
+	// DimensionSeverity is the dimension holding the severity the error was logged at.
+	DimensionSeverity = "severity"
+	// MissingDimensionValue is used for the dimensions missing in the error, as CloudWatch drops the metrics with
+	// missing dimensions.
+	MissingDimensionValue = "none"
+	// DimensionKeyPrefix prefixes the dimension keys colliding with the keys of the EMF metadata, the code and
+	// severity dimensions, the error context properties or the metric name, e.g. the `error` dimension is written as
+	// `dimension_error`.
+	DimensionKeyPrefix = "dimension_"
+	// MetricNamePrefix prefixes the metric name colliding with the keys of the EMF metadata, the code and severity
+	// dimensions or the error context properties.
+	MetricNamePrefix = "metric_"
+
+	metadataKey = "_aws"
+)
+
+// reservedKeys are the top-level keys of the EMF log line other than the dimensions and the metric, see
+// errors.GetMergedLogFields.
+var reservedKeys = map[string]bool{
+	metadataKey:         true,
+	DimensionCode:       true,
+	DimensionSeverity:   true,
+	"error":             true,
+	"error_context":     true,
+	"error_docs_url":    true,
+	"error_owner":       true,
+	"error_fingerprint": true,
+	"aws_error_code":    true,
+	"aws_error_message": true,
+}
+
+// Option configures Formatter.
+type Option func(f *Formatter)
+
+// WithDimensions adds the field keys used as the metric dimensions in addition to DimensionCode and
+// DimensionSeverity, e.g. errors.KeyTenantID. The dimension values are taken from the merged error fields.
+func WithDimensions(keys ...string) Option {
+	return func(f *Formatter) {
+		f.dimensions = append(f.dimensions, keys...)
+	}
+}
+
+// WithMetricName sets the name of the error count metric.
+func WithMetricName(name string) Option {
+	return func(f *Formatter) {
+		f.metricName = name
+	}
+}
+
+// Formatter formats the errors as CloudWatch EMF JSON log lines. Every line holds the error count metric with the
+// dimensions and the error context (see errors.GetMergedLogFields) as the properties. The dimension keys and the
+// metric name colliding with the other properties are prefixed with DimensionKeyPrefix and MetricNamePrefix.
+type Formatter struct {
+	namespace  string
+	dimensions []string
+	// dimensionNames are the property names of the dimensions, the dimension keys prefixed if reserved.
+	dimensionNames []string
+	metricName     string
+	now            func() time.Time
+}
+
+// NewFormatter returns Formatter of the metrics in the CloudWatch namespace.
+func NewFormatter(namespace string, opts ...Option) *Formatter {
+	f := &Formatter{
+		namespace:  namespace,
+		metricName: DefaultMetricName,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if reservedKeys[f.metricName] {
+		f.metricName = MetricNamePrefix + f.metricName
+	}
+	for _, key := range f.dimensions {
+		if reservedKeys[key] || key == f.metricName {
+			key = DimensionKeyPrefix + key
+		}
+		f.dimensionNames = append(f.dimensionNames, key)
+	}
+	return f
+}
+
+// Format returns the EMF JSON log line of the error with the error severity, without the trailing new line.
+func (f *Formatter) Format(err error) ([]byte, error) {
+	return f.format(err, errors.GetErrorSeverity(err))
+}
+
+// Write writes the EMF JSON log line of the error with the error severity to w.
+func (f *Formatter) Write(w io.Writer, err error) error {
+	return f.write(w, err, errors.GetErrorSeverity(err))
+}
+
+// Observer returns errors.LogObserver writing the EMF log lines of the logged errors to w, e.g. os.Stdout. Use
+// `errors.AddLogObserver(formatter.Observer(os.Stdout))` to enable it. Write errors are logged with the logrus
+// standard logger at warning level.
+func (f *Formatter) Observer(w io.Writer) errors.LogObserver {
+	var mu sync.Mutex
+	return func(err error, severity errors.LogSeverity) {
+		mu.Lock()
+		defer mu.Unlock()
+		if writeErr := f.write(w, err, severity); writeErr != nil {
+			// The error is logged with logrus directly, as the package logging functions would call the observer again.
+			logrus.WithError(writeErr).Warn("failed to write EMF log line")
+		}
+	}
+}
+
+func (f *Formatter) write(w io.Writer, err error, severity errors.LogSeverity) error {
+	line, formatErr := f.format(err, severity)
+	if formatErr != nil {
+		return formatErr
+	}
+	_, writeErr := w.Write(append(line, '\n'))
+	return writeErr
+}
+
+type metadata struct {
+	Timestamp         int64             `json:"Timestamp"`
+	CloudWatchMetrics []metricDirective `json:"CloudWatchMetrics"`
+}
+
+type metricDirective struct {
+	Namespace  string             `json:"Namespace"`
+	Dimensions [][]string         `json:"Dimensions"`
+	Metrics    []metricDefinition `json:"Metrics"`
+}
+
+type metricDefinition struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+func (f *Formatter) format(err error, severity errors.LogSeverity) ([]byte, error) {
+	if err == nil {
+		return nil, errors.New("nil error can't be formatted")
+	}
+
+	details := errors.GetLogDetails(err)
+	line := map[string]interface{}{}
+	for k, v := range details.MergedLogFields() {
+		line[k] = v
+	}
+
+	dimensions := append([]string{DimensionCode, DimensionSeverity}, f.dimensionNames...)
+	line[DimensionCode] = dimensionValue(errors.GetCode(err))
+	line[DimensionSeverity] = dimensionValue(string(severity))
+	var fields errors.Fields
+	if details.NCError != nil {
+		fields = details.NCError.GetMergedFields()
+	}
+	for i, key := range f.dimensions {
+		val, ok := fields[key]
+		if !ok {
+			line[f.dimensionNames[i]] = MissingDimensionValue
+			continue
+		}
+		line[f.dimensionNames[i]] = dimensionValue(fmt.Sprint(val))
+	}
+
+	line[f.metricName] = 1
+	line[metadataKey] = metadata{
+		Timestamp: f.now().UnixMilli(),
+		CloudWatchMetrics: []metricDirective{{
+			Namespace:  f.namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    []metricDefinition{{Name: f.metricName, Unit: "Count"}},
+		}},
+	}
+
+	data, marshalErr := json.Marshal(line)
+	if marshalErr != nil {
+		return nil, errors.Wrap(marshalErr, "marshal EMF log line")
+	}
+	return data, nil
+}
+
+func dimensionValue(val string) string {
+	if val == "" {
+		return MissingDimensionValue
+	}
+	return val
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package emferrors
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFormatter(opts ...Option) *Formatter {
+	f := NewFormatter("Test", opts...)
+	f.now = func() time.Time { return time.UnixMilli(1672531200000) }
+	return f
+}
+
+func TestFormat(t *testing.T) {
+	f := newTestFormatter(WithDimensions(errors.KeyTenantID, "service"))
+
+	awsErr := awserr.New("Throttling", "rate exceeded", nil)
+	err := errors.WithContext(awsErr, "call", errors.TenantID("t1"), errors.Fields{"token": "t0ken"})
+	err = errors.WithCode(err, "CALL_FAILED")
+	data, formatErr := f.Format(err)
+	assert.NoError(t, formatErr)
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &line))
+	assert.Equal(t, map[string]interface{}{
+		"Timestamp": 1672531200000.0,
+		"CloudWatchMetrics": []interface{}{map[string]interface{}{
+			"Namespace":  "Test",
+			"Dimensions": []interface{}{[]interface{}{"code", "severity", "tenant_id", "service"}},
+			"Metrics":    []interface{}{map[string]interface{}{"Name": "Errors", "Unit": "Count"}},
+		}},
+	}, line["_aws"])
+	assert.Equal(t, 1.0, line["Errors"])
+	assert.Equal(t, "CALL_FAILED", line["code"])
+	assert.Equal(t, "error", line["severity"])
+	assert.Equal(t, "t1", line["tenant_id"])
+	assert.Equal(t, MissingDimensionValue, line["service"])
+
+	// Error context properties.
+	assert.Equal(t, err.Error(), line["error"])
+	assert.Equal(t, "Throttling", line["aws_error_code"])
+	assert.Equal(t, errors.Fingerprint(err), line["error_fingerprint"])
+	errCtx := line["error_context"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"tenant_id": "t1", "token": errors.RedactedValue}, errCtx["fields"])
+	assert.NotEmpty(t, errCtx["stack"])
+}
+
+func TestFormat_StandardError(t *testing.T) {
+	f := newTestFormatter(WithDimensions(errors.KeyTenantID), WithMetricName("Failures"))
+
+	data, err := f.Format(stderrors.New("std"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"_aws": {
+			"Timestamp": 1672531200000,
+			"CloudWatchMetrics": [{
+				"Namespace": "Test",
+				"Dimensions": [["code", "severity", "tenant_id"]],
+				"Metrics": [{"Name": "Failures", "Unit": "Count"}]
+			}]
+		},
+		"Failures": 1,
+		"code": "none",
+		"severity": "error",
+		"tenant_id": "none",
+		"error": "std"
+	}`, string(data))
+
+	_, err = f.Format(nil)
+	assert.Error(t, err)
+}
+
+func TestObserver(t *testing.T) {
+	var buf bytes.Buffer
+	remove := errors.AddLogObserver(newTestFormatter().Observer(&buf))
+	defer remove()
+
+	errors.LogWarning(errors.New("error1"))
+	errors.LogWithSeverity(errors.NewWithSeverity("error2", nil, errors.INFO))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal(t, "warning", line["severity"])
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &line))
+	assert.Equal(t, "info", line["severity"])
+	assert.Equal(t, "error2", line["error"])
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, newTestFormatter().Write(&buf, errors.New("error")))
+	assert.True(t, strings.HasSuffix(buf.String(), "}\n"))
+}
+
+func TestFormat_ReservedKeys(t *testing.T) {
+	f := newTestFormatter(WithDimensions("error", "_aws", "Errors", "service"), WithMetricName("severity"))
+
+	err := errors.New("failed", errors.Fields{"error": "e1", "_aws": "a1", "Errors": "m1", "service": "s1"})
+	data, formatErr := f.Format(err)
+	assert.NoError(t, formatErr)
+
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &line))
+	assert.Equal(t, "failed", line["error"])
+	assert.Equal(t, "error", line["severity"])
+	assert.Equal(t, 1.0, line["metric_severity"])
+	assert.Equal(t, "e1", line["dimension_error"])
+	assert.Equal(t, "a1", line["dimension__aws"])
+	assert.Equal(t, "m1", line["Errors"])
+	assert.Equal(t, "s1", line["service"])
+	directive := line["_aws"].(map[string]interface{})["CloudWatchMetrics"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{[]interface{}{"code", "severity", "dimension_error", "dimension__aws", "Errors",
+		"service"}}, directive["Dimensions"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"Name": "metric_severity", "Unit": "Count"}},
+		directive["Metrics"])
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, stderrors.New("closed")
+}
+
+func TestObserver_WriteError(t *testing.T) {
+	hook := test.NewGlobal()
+	remove := errors.AddLogObserver(newTestFormatter().Observer(failingWriter{}))
+	defer remove()
+
+	errors.LogError(errors.New("error1"))
+
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Equal(t, "failed to write EMF log line", entries[0].Message)
+	assert.EqualError(t, entries[0].Data[logrus.ErrorKey].(error), "closed")
+	assert.Equal(t, "error1", entries[1].Message)
+}
@@ -62,36 +62,20 @@ func (f Fields) copy() Fields {
 	return newFields
 }
 
-// mergeFields merges slice of Fields. Rightmost Fields key-values overrides left Fields key-values when keys are
-// duplicated. It is added to handle Fields slice when more than 1 Fields is passed. This function allows to make Fields
-// optional in New, Wrap and other functions.
-func mergeFields(fields ...Fields) Fields {
-	if len(fields) == 0 {
-		return nil
-	}
-
-	if len(fields) == 1 {
-		return fields[0]
-	}
-
-	merged := make(Fields)
-	for _, m := range fields {
-		for k, v := range m {
-			merged[k] = v
-		}
-	}
-
-	return merged
-}
-
 // Cause keeps the context information about the error.
 type Cause struct {
-	Message  string
-	Fields   Fields
-	FuncName string
-	FileName string
-	Line     int
-	Severity LogSeverity
+	Message       string
+	Fields        Fields
+	FuncName      string
+	FileName      string
+	Line          int
+	Severity      LogSeverity
+	Code          string
+	Kind          Kind
+	PublicMessage string
+	Hint          string
+	DocsURL       string
+	Owner         string
 }
 
 // NCError basic error structure.
@@ -106,6 +90,9 @@ type NCError struct {
 	RawStack *stack
 	//The root error at the base level.
 	RootError error
+	// wrapped is the original chain of the non-NCError wrappers when the NCError was found behind them (see
+	// unwrapNCError), so they remain reachable with Is and As.
+	wrapped error
 }
 
 func (n NCError) Error() string {
@@ -124,20 +111,7 @@ func (n NCError) StackTrace() errors.StackTrace {
 
 // New error with context.
 func New(message string, fields ...Fields) error {
-	fileName, funcName, lineNumber := GetRuntimeContext()
-	newCause := Cause{
-		Message:  message,
-		Fields:   mergeFields(fields...),
-		FuncName: funcName,
-		FileName: fileName,
-		Line:     lineNumber,
-		Severity: ERROR}
-	stack, rawStack := getStackTraces()
-	return NCError{
-		Causes:   []Cause{newCause},
-		Stack:    stack,
-		RawStack: rawStack,
-	}
+	return newError(1, message, ERROR, fields)
 }
 
 func NewWithSeverity(message string, fields Fields, severity LogSeverity) error {
@@ -160,35 +134,38 @@ func NewWithSeverity(message string, fields Fields, severity LogSeverity) error
 
 // WithContext set new error wrapped with message and error context.
 func WithContext(err error, message string, fields ...Fields) error {
-	// Attach message to the list of causes.
-	fileName, funcName, lineNumber := GetRuntimeContext()
+	return withContext(1, err, message, ERROR, fields)
+}
+
+// WithContextAndSeverity set new error wrapped with message, severity and error context.
+func WithContextAndSeverity(err error, message string, severity LogSeverity, fields ...Fields) error {
+	return withContext(1, err, message, severity, fields)
+}
+
+// newError creates the error. The skip is the number of the frames between newError and the caller to be recorded.
+func newError(skip int, message string, severity LogSeverity, fields []Fields) error {
+	fileName, funcName, lineNumber := getRuntimeContextSkip(skip)
 	newCause := Cause{
 		Message:  message,
 		Fields:   mergeFields(fields...),
 		FuncName: funcName,
 		FileName: fileName,
 		Line:     lineNumber,
-		Severity: ERROR,
-	}
-	//If we wrap existing NCError at the higher layer. Here we only append causes.
-	//and do not touch stack trace and root error.
-	if ncError, ok := err.(NCError); ok {
-		ncError.Causes = append([]Cause{newCause}, ncError.Causes...)
-		return ncError
+		Severity: severity,
 	}
-
-	stack, rawStack := getStackTraces()
+	stack, rawStack := getStackTracesSkip(skip)
 	return NCError{
-		Causes:    []Cause{newCause, Cause{Message: err.Error()}},
-		Stack:     stack,
-		RawStack:  rawStack,
-		RootError: err}
+		Causes:   []Cause{newCause},
+		Stack:    stack,
+		RawStack: rawStack,
+	}
 }
 
-// WithContextAndSeverity set new error wrapped with message, severity and error context.
-func WithContextAndSeverity(err error, message string, severity LogSeverity, fields ...Fields) error {
+// withContext wraps err with the message. The skip is the number of the frames between withContext and the caller to
+// be recorded.
+func withContext(skip int, err error, message string, severity LogSeverity, fields []Fields) error {
 	// Attach message to the list of causes.
-	fileName, funcName, lineNumber := GetRuntimeContext()
+	fileName, funcName, lineNumber := getRuntimeContextSkip(skip)
 	newCause := Cause{
 		Message:  message,
 		Fields:   mergeFields(fields...),
@@ -199,14 +176,15 @@ func WithContextAndSeverity(err error, message string, severity LogSeverity, fie
 	}
 	//If we wrap existing NCError at the higher layer. Here we only append causes.
 	//and do not touch stack trace and root error.
-	if ncError, ok := err.(NCError); ok {
-		ncError.Causes = append([]Cause{newCause}, ncError.Causes...)
+	if ncError, wrapperCauses, ok := unwrapNCError(err); ok {
+		causes := append([]Cause{newCause}, wrapperCauses...)
+		ncError.Causes = append(causes, ncError.Causes...)
 		return ncError
 	}
 
-	stack, rawStack := getStackTraces()
+	stack, rawStack := getStackTracesSkip(skip)
 	return NCError{
-		Causes:    []Cause{newCause, Cause{Message: err.Error()}},
+		Causes:    []Cause{newCause, {Message: err.Error()}},
 		Stack:     stack,
 		RawStack:  rawStack,
 		RootError: err,
@@ -223,19 +201,11 @@ func (n *NCError) GetContext() Fields {
 
 // GetMergedFields returns fields from the error.
 // The custom fields are merged from every error's cause's fields (as defined by WithContext invocations).
-// If the field with the same name is present in multiple causes the value from the outermost cause is taken.
+// If the field with the same name is present in multiple causes the value from the outermost cause is taken, unless
+// changed with SetMergeOptions.
 // This will be used for logrus.WithFields method.
 func (n *NCError) GetMergedFields() Fields {
-	errFields := Fields{}
-	for _, cause := range n.Causes {
-		for k, v := range cause.Fields {
-			if _, exists := errFields[k]; !exists {
-				errFields[k] = v
-			}
-		}
-	}
-
-	return errFields
+	return n.MergeFields(getMergeOptions()...)
 }
 
 // GetMergedFieldsContext returns error stack and merged fields.
@@ -248,7 +218,7 @@ func (n *NCError) GetMergedFieldsContext() Fields {
 
 // GetErrorSeverity returns outermost NCError severity or ERROR level.
 func GetErrorSeverity(err error) LogSeverity {
-	if ncError, ok := err.(NCError); ok {
+	if ncError, ok := AsNC(err); ok {
 		if len(ncError.Causes) > 0 {
 			return ncError.Causes[0].Severity
 		}
@@ -259,7 +229,7 @@ func GetErrorSeverity(err error) LogSeverity {
 
 // GetRootError returns root error.
 func GetRootError(err error) error {
-	if ncError, ok := err.(NCError); ok && ncError.RootError != nil {
+	if ncError, ok := AsNC(err); ok && ncError.RootError != nil {
 		return ncError.RootError
 	}
 	return err
@@ -276,36 +246,138 @@ func Wrap(err error, message string, fields ...Fields) error {
 		return nil
 	}
 
-	return WithContext(err, message, fields...)
+	return withContext(1, err, message, ERROR, fields)
 }
 
 // Is checks if given error is equal. Solution is quite weird due to awkward wrap design.
+// Errors with the code (see NewCode) are equal also if any of the causes has the same code. The non-NCError wrappers
+// the error was unwrapped from (e.g. when wrapping `fmt.Errorf("x: %w", ncErr)`) are searched too.
 func (n NCError) Is(target error) bool {
-	if err, ok := target.(*NCError); ok {
+	if err, ok := toNCError(target); ok && len(err.Causes) > 0 {
+		targetCause := err.Causes[len(err.Causes)-1]
 		for _, v := range n.Causes {
-			if v.Message == err.Causes[len(err.Causes)-1].Message {
+			if v.Message == targetCause.Message || (targetCause.Code != "" && v.Code == targetCause.Code) {
 				return true
 			}
 		}
 	}
 
-	if err, ok := target.(NCError); ok {
-		for _, v := range n.Causes {
-			if v.Message == err.Causes[len(err.Causes)-1].Message {
+	return n.wrapped != nil && Is(n.wrapped, target)
+}
+
+// As populates target with the error if target is either *NCError or **NCError. Other targets are searched in the
+// non-NCError wrappers the error was unwrapped from, see Is.
+func (n NCError) As(target any) bool {
+	switch t := target.(type) {
+	case *NCError:
+		*t = n
+		return true
+	case **NCError:
+		nc := n
+		*t = &nc
+		return true
+	}
+
+	return n.wrapped != nil && as(n.wrapped, target)
+}
+
+// AsNC returns the first NCError found in the err tree. NCErrors stored by pointer are dereferenced.
+func AsNC(err error) (NCError, bool) {
+	var ncError NCError
+	if err == nil {
+		return ncError, false
+	}
+	ok := as(err, &ncError)
+	return ncError, ok
+}
+
+// AsType returns the first error in the err tree matching type T. The tree is searched through NCError, its RootError
+// and multi-errors (errors implementing `Unwrap() []error`).
+func AsType[T error](err error) (T, bool) {
+	var target T
+	if err == nil {
+		return target, false
+	}
+	ok := as(err, &target)
+	return target, ok
+}
+
+// as is errors.As skipping nil *NCError errors, which would panic when their value methods (e.g. As) are called. The
+// target must be a non-nil pointer.
+func as(err error, target any) bool {
+	if err == nil {
+		return false
+	}
+	if ncError, ok := err.(*NCError); ok && ncError == nil {
+		return false
+	}
+
+	targetValue := reflect.ValueOf(target).Elem()
+	if reflect.TypeOf(err).AssignableTo(targetValue.Type()) {
+		targetValue.Set(reflect.ValueOf(err))
+		return true
+	}
+	if x, ok := err.(interface{ As(any) bool }); ok && x.As(target) {
+		return true
+	}
+	switch x := err.(type) {
+	case interface{ Unwrap() error }:
+		return as(x.Unwrap(), target)
+	case interface{ Unwrap() []error }:
+		for _, err := range x.Unwrap() {
+			if as(err, target) {
 				return true
 			}
 		}
 	}
-
 	return false
 }
 
-// As checks if given error type is equal.
-func (n NCError) As(target any) bool {
-	t := reflect.Indirect(reflect.ValueOf(target)).Interface()
-	if _, ok := t.(*NCError); ok {
-		return true
+// toNCError returns err as NCError if it is either NCError or non-nil *NCError. Unlike AsNC the err chain is not
+// searched.
+func toNCError(err error) (NCError, bool) {
+	switch e := err.(type) {
+	case NCError:
+		return e, true
+	case *NCError:
+		if e != nil {
+			return *e, true
+		}
 	}
 
-	return false
+	return NCError{}, false
+}
+
+// unwrapNCError searches the err chain for NCError. Messages of the non-NCError wrappers found on the way to the
+// NCError are returned as causes, outermost first. Only the wrappers prefixing the wrapped message (e.g.
+// `fmt.Errorf("x: %w", ncErr)`) add the cause, the wrappers without own message (e.g. github.com/pkg/errors.WithStack)
+// or with a different form are skipped. The wrapper causes inherit the severity of the NCError's outermost cause, so
+// wrapping doesn't change the severity the error is logged with. The returned NCError keeps the original err, so the
+// wrappers remain reachable with Is and As.
+func unwrapNCError(err error) (NCError, []Cause, bool) {
+	var wrapperCauses []Cause
+	outer := err
+	for depth := 0; err != nil; depth, err = depth+1, Unwrap(err) {
+		if ncError, ok := toNCError(err); ok {
+			if len(ncError.Causes) > 0 {
+				for i := range wrapperCauses {
+					wrapperCauses[i].Severity = ncError.Causes[0].Severity
+				}
+			}
+			if depth > 0 {
+				ncError.wrapped = outer
+			}
+			return ncError, wrapperCauses, true
+		}
+
+		inner := Unwrap(err)
+		if inner == nil {
+			break
+		}
+		if message, ok := strings.CutSuffix(err.Error(), ": "+inner.Error()); ok && message != "" {
+			wrapperCauses = append(wrapperCauses, Cause{Message: message})
+		}
+	}
+
+	return NCError{}, nil, false
 }
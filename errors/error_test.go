@@ -179,6 +179,47 @@ func TestErrorSeverity_Single(t *testing.T) {
 	assert.Equal(t, WARN, severity)
 }
 
+func TestErrorSeverity_Pointer(t *testing.T) {
+	err := NewWithSeverity("error1", nil, WARN).(NCError)
+	assert.Equal(t, WARN, GetErrorSeverity(&err))
+	assert.Equal(t, WARN, GetErrorSeverity(fmt.Errorf("std wrap: %w", &err)))
+	assert.Equal(t, WARN, GetErrorSeverity(errors.Wrap(err, "pkg wrap")))
+}
+
+func TestWithContext_Pointer(t *testing.T) {
+	err := NewWithSeverity("error1", nil, WARN).(NCError)
+	wrapped := WithContext(&err, "level2").(NCError)
+
+	assert.Equal(t, "level2: error1", wrapped.Error())
+	assert.Equal(t, err.Stack, wrapped.Stack)
+	assert.Nil(t, wrapped.RootError)
+	assert.Len(t, wrapped.Causes, 2)
+	assert.Equal(t, WARN, wrapped.Causes[1].Severity)
+	// The original error remains intact.
+	assert.Len(t, err.Causes, 1)
+}
+
+func TestWithContext_NestedInStdWrappers(t *testing.T) {
+	rootErr := errors.New("root")
+	ncErr := WithContextAndSeverity(rootErr, "level1", WARN, Fields{"field1": "val1"}).(NCError)
+	stdErr := fmt.Errorf("std2: %w", errors.WithStack(fmt.Errorf("std1: %w", ncErr)))
+
+	wrapped := WithContext(stdErr, "level2", Fields{"field2": "val2"}).(NCError)
+
+	assert.Equal(t, "level2: "+stdErr.Error(), wrapped.Error())
+	assert.Equal(t, ncErr.Stack, wrapped.Stack)
+	assert.Equal(t, ncErr.RawStack, wrapped.RawStack)
+	assert.Equal(t, rootErr, wrapped.RootError)
+
+	var messages []string
+	for _, cause := range wrapped.Causes {
+		messages = append(messages, cause.Message)
+	}
+	assert.Equal(t, []string{"level2", "std2", "std1", "level1", "root"}, messages)
+	assert.Equal(t, Fields{"field1": "val1", "field2": "val2"}, wrapped.GetMergedFields())
+	assert.Equal(t, WARN, wrapped.Causes[3].Severity)
+}
+
 func TestGetRootError(t *testing.T) {
 	rootError := errors.New("Root error")
 	wrappedError := WithContext(rootError, "second error", Fields{"123": "456"})
@@ -191,6 +232,9 @@ func TestGetRootError(t *testing.T) {
 		ncErrorNew,
 	}
 
+	wrappedNCError := wrappedError.(NCError)
+	testCases = append(testCases, &wrappedNCError, fmt.Errorf("std wrap: %w", &wrappedNCError))
+
 	for _, testCase := range testCases {
 		res := GetRootError(testCase)
 		if res != nil {
@@ -284,6 +328,68 @@ func TestAs(t *testing.T) {
 
 	stdWrapErr := errors.Wrap(err, "wrap test")
 	assert.True(t, As(stdWrapErr, &e))
+	assert.NotNil(t, e)
+	assert.Equal(t, "test", e.Error())
+}
+
+func TestAs_Value(t *testing.T) {
+	var e NCError
+
+	err := fmt.Errorf("std wrap: %w", Wrap(ErrTest, "wrap test"))
+	assert.True(t, As(err, &e))
+	assert.Equal(t, "wrap test: test", e.Error())
+	assert.Len(t, e.Causes, 2)
+}
+
+func TestAs_Pointer(t *testing.T) {
+	var e *NCError
+
+	ncErr := ErrTest.(NCError)
+	err := fmt.Errorf("std wrap: %w", &ncErr)
+	assert.True(t, As(err, &e))
+	assert.Equal(t, "test", e.Error())
+}
+
+func TestAsNC(t *testing.T) {
+	_, ok := AsNC(nil)
+	assert.False(t, ok)
+
+	_, ok = AsNC(errors.New("std"))
+	assert.False(t, ok)
+
+	ncErr, ok := AsNC(fmt.Errorf("std wrap: %w", Wrap(ErrTest, "wrap test")))
+	assert.True(t, ok)
+	assert.Equal(t, "wrap test: test", ncErr.Error())
+}
+
+type testTypedError struct {
+	code string
+}
+
+func (e testTypedError) Error() string {
+	return e.code
+}
+
+func TestAsType(t *testing.T) {
+	_, ok := AsType[testTypedError](nil)
+	assert.False(t, ok)
+
+	rootErr := testTypedError{code: "code1"}
+	typedErr, ok := AsType[testTypedError](Wrap(rootErr, "wrap test"))
+	assert.True(t, ok)
+	assert.Equal(t, rootErr, typedErr)
+
+	joinedErr := Join(errors.New("std"), fmt.Errorf("std wrap: %w", Wrap(rootErr, "wrap test")))
+	typedErr, ok = AsType[testTypedError](joinedErr)
+	assert.True(t, ok)
+	assert.Equal(t, rootErr, typedErr)
+
+	ncErr, ok := AsType[NCError](joinedErr)
+	assert.True(t, ok)
+	assert.Equal(t, "wrap test: code1", ncErr.Error())
+
+	_, ok = AsType[*testTypedError](joinedErr)
+	assert.False(t, ok)
 }
 
 func Test_mergeFields(t *testing.T) {
@@ -355,3 +461,44 @@ func Test_mergeFields(t *testing.T) {
 		})
 	}
 }
+
+type testWrapper struct {
+	err error
+}
+
+func (w testWrapper) Error() string { return "custom(" + w.err.Error() + ")" }
+func (w testWrapper) Unwrap() error { return w.err }
+
+func TestWithContext_NonPrefixWrappers(t *testing.T) {
+	ncErr := New("level1")
+
+	wrapped := WithContext(fmt.Errorf("%w (while syncing)", ncErr), "level2")
+	assert.Equal(t, "level2: level1", wrapped.Error())
+
+	wrapped = WithContext(testWrapper{err: fmt.Errorf("std: %w", ncErr)}, "level2")
+	assert.Equal(t, "level2: std: level1", wrapped.Error())
+}
+
+func TestAnnotate_KeepsWrappers(t *testing.T) {
+	sentinel := errors.New("sentinel")
+	ncErr := Wrap(sentinel, "level1")
+	err := WithFields(testWrapper{err: fmt.Errorf("std: %w", ncErr)}, Fields{"id": 1})
+
+	var wrapper testWrapper
+	assert.True(t, As(err, &wrapper))
+	assert.Equal(t, "custom(std: level1: sentinel)", wrapper.Error())
+	assert.True(t, Is(err, sentinel))
+	assert.True(t, Is(err, ncErr))
+	ncError, ok := AsNC(err)
+	assert.True(t, ok)
+	assert.Equal(t, Fields{"id": 1}, ncError.Causes[0].Fields)
+	assert.Equal(t, "std: level1: sentinel", err.Error())
+}
+
+func TestWrap_Caller(t *testing.T) {
+	err := Wrap(errors.New("root"), "level1").(NCError)
+
+	assert.Equal(t, "TestWrap_Caller", err.Causes[0].FuncName)
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/error.go(Wrap)", frameNames(err.Stack)[0])
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/error_test.go(TestWrap_Caller)", frameNames(err.Stack)[1])
+}
@@ -0,0 +1,191 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Canonical keys of the well-known fields.
+const (
+	KeyAccountID   = "account_id"
+	KeyRegion      = "region"
+	KeyTenantID    = "tenant_id"
+	KeyResourceARN = "resource_arn"
+	KeyRequestID   = "request_id"
+	KeyUserID      = "user_id"
+)
+
+var (
+	fieldTypesMu sync.RWMutex
+	fieldTypes   = map[string]reflect.Type{
+		KeyAccountID:   reflect.TypeOf(""),
+		KeyRegion:      reflect.TypeOf(""),
+		KeyTenantID:    reflect.TypeOf(""),
+		KeyResourceARN: reflect.TypeOf(""),
+		KeyRequestID:   reflect.TypeOf(""),
+		KeyUserID:      reflect.TypeOf(""),
+	}
+)
+
+// AccountID returns Fields with the cloud account ID under KeyAccountID.
+func AccountID(id string) Fields {
+	return Fields{KeyAccountID: id}
+}
+
+// Region returns Fields with the cloud region under KeyRegion.
+func Region(region string) Fields {
+	return Fields{KeyRegion: region}
+}
+
+// TenantID returns Fields with the tenant ID under KeyTenantID.
+func TenantID(id string) Fields {
+	return Fields{KeyTenantID: id}
+}
+
+// ResourceARN returns Fields with the resource ARN under KeyResourceARN.
+func ResourceARN(arn string) Fields {
+	return Fields{KeyResourceARN: arn}
+}
+
+// RequestID returns Fields with the request ID under KeyRequestID.
+func RequestID(id string) Fields {
+	return Fields{KeyRequestID: id}
+}
+
+// UserID returns Fields with the user ID under KeyUserID.
+func UserID(id string) Fields {
+	return Fields{KeyUserID: id}
+}
+
+// WithFields adds the fields to the outermost cause of the error without changing the error message. The added
+// fields override the cause fields with the same keys. Non-NCError errors are wrapped into NCError.
+func WithFields(err error, fields ...Fields) error {
+	return annotate(1, err, func(cause *Cause) {
+		cause.Fields = cause.Fields.Extend(mergeFields(fields...))
+	})
+}
+
+// WithDefaultFields adds the fields to the outermost cause of the error like WithFields, but only those with the keys
+// not set in any of the error's causes, so the fields already set on the error are kept. Use it to add the fields
+// known to the wrappers, e.g. the request ID, which the handler may have set more precisely.
+func WithDefaultFields(err error, fields ...Fields) error {
+	var present Fields
+	if ncError, ok := AsNC(err); ok {
+		present = ncError.MergeFields()
+	}
+
+	missing := Fields{}
+	for key, value := range mergeFields(fields...) {
+		if _, ok := present[key]; !ok {
+			missing[key] = value
+		}
+	}
+	return annotate(1, err, func(cause *Cause) {
+		cause.Fields = cause.Fields.Extend(missing)
+	})
+}
+
+// GetField returns value of the field from the error's causes. The causes are searched from the outermost one (or the
+// innermost one for the innermost-wins keys), the same way as in GetMergedFields. It returns false if the field is not
+// found or its value is not of type T.
+func GetField[T any](err error, key string) (T, bool) {
+	var zero T
+	ncError, ok := AsNC(err)
+	if !ok {
+		return zero, false
+	}
+
+	innermostWins := newMergeConfig(getMergeOptions()).innermostWins[key]
+	for i := range ncError.Causes {
+		cause := ncError.Causes[i]
+		if innermostWins {
+			cause = ncError.Causes[len(ncError.Causes)-1-i]
+		}
+		if val, exists := cause.Fields[key]; exists {
+			typed, ok := val.(T)
+			return typed, ok
+		}
+	}
+
+	return zero, false
+}
+
+// RegisterFieldType registers the expected type T of the field values stored under the key. The types are checked by
+// Fields.Validate and ValidateFieldTypes. The well-known keys are registered as strings.
+func RegisterFieldType[T any](key string) {
+	fieldTypesMu.Lock()
+	defer fieldTypesMu.Unlock()
+
+	fieldTypes[key] = reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Validate checks if values of the registered keys (see RegisterFieldType) have the expected types.
+func (f Fields) Validate() error {
+	var errs []error
+	for _, key := range sortedKeys(f) {
+		if err := checkFieldType(key, reflect.TypeOf(f[key])); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return ListToError(errs)
+}
+
+// ValidateFieldTypes checks if the same key has values of the same type in all the error's causes and if values of
+// the registered keys have the expected types. It returns nil for non-NCError errors.
+func ValidateFieldTypes(err error) error {
+	ncError, ok := AsNC(err)
+	if !ok {
+		return nil
+	}
+
+	types := map[string]reflect.Type{}
+	conflicts := map[string]bool{}
+	for _, cause := range ncError.Causes {
+		for key, val := range cause.Fields {
+			typ := reflect.TypeOf(val)
+			if known, exists := types[key]; exists && known != typ {
+				conflicts[key] = true
+				continue
+			}
+			types[key] = typ
+		}
+	}
+
+	var errs []error
+	for _, key := range sortedKeys(types) {
+		if conflicts[key] {
+			errs = append(errs, fmt.Errorf("field %q has conflicting types in causes", key))
+			continue
+		}
+		if err := checkFieldType(key, types[key]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return ListToError(errs)
+}
+
+func checkFieldType(key string, typ reflect.Type) error {
+	fieldTypesMu.RLock()
+	expected, registered := fieldTypes[key]
+	fieldTypesMu.RUnlock()
+
+	if registered && typ != expected {
+		return fmt.Errorf("field %q has type %v, expected %v", key, typ, expected)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
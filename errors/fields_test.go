@@ -0,0 +1,106 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWellKnownFields(t *testing.T) {
+	err := New("error1", AccountID("123"), Region("eu-west-1"), TenantID("t1"))
+	ncErr := Wrap(err, "level2", RequestID("req1"), UserID("u1"), ResourceARN("arn:aws:s3:::bucket")).(NCError)
+
+	assert.Equal(t, Fields{
+		KeyAccountID:   "123",
+		KeyRegion:      "eu-west-1",
+		KeyTenantID:    "t1",
+		KeyRequestID:   "req1",
+		KeyUserID:      "u1",
+		KeyResourceARN: "arn:aws:s3:::bucket",
+	}, ncErr.GetMergedFields())
+}
+
+func TestGetField(t *testing.T) {
+	err := New("error1", AccountID("123"), Fields{"count": 1})
+	err = Wrap(err, "level2", AccountID("456"))
+	err = fmt.Errorf("std wrap: %w", err)
+
+	accountID, ok := GetField[string](err, KeyAccountID)
+	assert.True(t, ok)
+	assert.Equal(t, "456", accountID)
+
+	count, ok := GetField[int](err, "count")
+	assert.True(t, ok)
+	assert.Equal(t, 1, count)
+
+	_, ok = GetField[string](err, "count")
+	assert.False(t, ok)
+
+	_, ok = GetField[string](err, KeyRegion)
+	assert.False(t, ok)
+
+	_, ok = GetField[string](errors.New("std"), KeyAccountID)
+	assert.False(t, ok)
+}
+
+func TestFieldsValidate(t *testing.T) {
+	assert.NoError(t, AccountID("123").Extend(Fields{"other": 1}).Validate())
+	assert.EqualError(t, Fields{KeyAccountID: 123, KeyRegion: "eu-west-1"}.Validate(),
+		`[field "account_id" has type int, expected string]`)
+
+	RegisterFieldType[int]("test_count")
+	assert.EqualError(t, Fields{"test_count": "1"}.Validate(), `[field "test_count" has type string, expected int]`)
+	assert.NoError(t, Fields{"test_count": 1}.Validate())
+}
+
+func TestValidateFieldTypes(t *testing.T) {
+	assert.NoError(t, ValidateFieldTypes(errors.New("std")))
+
+	err := New("error1", AccountID("123"), Fields{"id": 1})
+	assert.NoError(t, ValidateFieldTypes(Wrap(err, "level2", Fields{"id": 2})))
+
+	err = Wrap(err, "level2", Fields{"id": "2", KeyRegion: 1})
+	assert.EqualError(t, ValidateFieldTypes(err),
+		`[field "id" has conflicting types in causes, field "region" has type int, expected string]`)
+}
+
+func TestWithFields(t *testing.T) {
+	assert.Nil(t, WithFields(nil, Fields{"key": "val"}))
+
+	inner := New("error1", Fields{"id": 1})
+	err := WithFields(Wrap(inner, "level2", Fields{"id": 2}), Fields{"id": 3}, RequestID("req1"))
+	assert.Equal(t, "level2: error1", err.Error())
+	assert.Equal(t, Fields{"id": 3, KeyRequestID: "req1"}, err.(NCError).Causes[0].Fields)
+	assert.Equal(t, Fields{"id": 1}, inner.(NCError).Causes[0].Fields)
+
+	err = WithFields(errors.New("std"), Fields{"id": 1})
+	assert.Equal(t, "std", err.Error())
+	id, _ := GetField[int](err, "id")
+	assert.Equal(t, 1, id)
+}
+
+func TestWithFields_WrappedSeverity(t *testing.T) {
+	err := fmt.Errorf("handler: %w", NewWithSeverity("warning1", nil, WARN))
+
+	err = WithFields(err, Fields{"id": 1})
+	assert.Equal(t, "handler: warning1", err.Error())
+	assert.Equal(t, WARN, GetErrorSeverity(err))
+}
+
+func TestWithDefaultFields(t *testing.T) {
+	assert.Nil(t, WithDefaultFields(nil, Fields{"key": "val"}))
+
+	err := WithDefaultFields(Wrap(New("error1", RequestID("api-req")), "level2"), RequestID("req1"), Fields{"id": 1})
+	assert.Equal(t, "level2: error1", err.Error())
+	assert.Equal(t, Fields{"id": 1}, err.(NCError).Causes[0].Fields)
+	requestID, _ := GetField[string](err, KeyRequestID)
+	assert.Equal(t, "api-req", requestID)
+
+	err = WithDefaultFields(errors.New("std"), RequestID("req1"))
+	requestID, _ = GetField[string](err, KeyRequestID)
+	assert.Equal(t, "req1", requestID)
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// DefaultFingerprintFrames is the number of the in-app stack frames included in the fingerprint by default.
+const DefaultFingerprintFrames = 3
+
+const thisPackage = "github.com/nordcloud/ncerrors/errors"
+
+var (
+	fingerprintOptions atomic.Pointer[[]FingerprintOption]
+
+	// messageTemplateReplacements turn the variable parts of the messages (IDs, numbers, quoted values) into
+	// placeholders. The order matters, e.g. UUIDs must be replaced before the numbers.
+	messageTemplateReplacements = []struct {
+		pattern     *regexp.Regexp
+		placeholder string
+	}{
+		{regexp.MustCompile(`"[^"]*"|'[^']*'|` + "`[^`]*`"), "<str>"},
+		{regexp.MustCompile(`arn:[^\s,;]*[^\s,;:]`), "<arn>"},
+		{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+		{regexp.MustCompile(`(?i)\b(0x[0-9a-f]+|[0-9a-f]{8,})\b`), "<hex>"},
+		{regexp.MustCompile(`\d+`), "<num>"},
+	}
+)
+
+// FingerprintOption configures Fingerprint.
+type FingerprintOption func(cfg *fingerprintConfig)
+
+type fingerprintConfig struct {
+	frames        int
+	ignoreLines   bool
+	inAppPrefixes []string
+}
+
+// WithFingerprintFrames sets the number of the top in-app stack frames included in the fingerprint. Zero excludes
+// the stack from the fingerprint.
+func WithFingerprintFrames(n int) FingerprintOption {
+	return func(cfg *fingerprintConfig) {
+		cfg.frames = n
+	}
+}
+
+// WithoutLineNumbers excludes the line numbers of the stack frames from the fingerprint, so it doesn't change when
+// the code around the error is edited.
+func WithoutLineNumbers() FingerprintOption {
+	return func(cfg *fingerprintConfig) {
+		cfg.ignoreLines = true
+	}
+}
+
+// WithInAppPrefixes limits the in-app stack frames to the functions with any of the package path prefixes, e.g.
+// "github.com/nordcloud/". By default, all the frames outside of the standard library and this package are in-app.
+func WithInAppPrefixes(prefixes ...string) FingerprintOption {
+	return func(cfg *fingerprintConfig) {
+		cfg.inAppPrefixes = append(cfg.inAppPrefixes, prefixes...)
+	}
+}
+
+// SetFingerprintOptions sets the options used for the fingerprint by all the logging functions and serializers of
+// this package.
+func SetFingerprintOptions(opts ...FingerprintOption) {
+	fingerprintOptions.Store(&opts)
+}
+
+func getFingerprintOptions() []FingerprintOption {
+	if opts := fingerprintOptions.Load(); opts != nil {
+		return *opts
+	}
+	return nil
+}
+
+// Fingerprint returns a stable hash of the error for grouping the same failures together. It is computed from the
+// codes of the causes (or their message templates if there is no code, see MessageTemplate), the AWS error code and
+// the top in-app frames of the stack trace. Fields are not included. The messages are redacted with the configured
+// Redactor first (see SetRedactor), so the fingerprint is the same for the error and its redacted copy in the logs,
+// and the messages differing only in the redacted parts are grouped together. It returns empty string for nil error.
+func Fingerprint(err error, opts ...FingerprintOption) string {
+	if err == nil {
+		return ""
+	}
+
+	cfg := fingerprintConfig{frames: DefaultFingerprintFrames}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	hash := sha256.New()
+	rootErr := err
+	if ncError, ok := AsNC(err); ok {
+		for _, cause := range ncError.Causes {
+			if cause.Code != "" {
+				fmt.Fprintf(hash, "code:%s\n", cause.Code)
+				continue
+			}
+			fmt.Fprintf(hash, "message:%s\n", MessageTemplate(RedactMessage(cause.Message)))
+		}
+		for _, frame := range inAppFrames(ncError.RawStack, cfg) {
+			fmt.Fprintf(hash, "frame:%s\n", frame)
+		}
+		rootErr = ncError.RootError
+	} else {
+		fmt.Fprintf(hash, "message:%s\n", MessageTemplate(RedactMessage(err.Error())))
+	}
+	if awsErr, ok := AsType[awserr.Error](rootErr); ok {
+		fmt.Fprintf(hash, "aws:%s\n", awsErr.Code())
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// MessageTemplate returns the message with the variable parts, e.g. quoted values, ARNs, UUIDs, hexadecimal IDs and
+// numbers, replaced with placeholders.
+func MessageTemplate(message string) string {
+	for _, r := range messageTemplateReplacements {
+		message = r.pattern.ReplaceAllString(message, r.placeholder)
+	}
+	return message
+}
+
+// inAppFrames returns the top in-app frames of the stack formatted as `function:line` or `function`.
+func inAppFrames(rawStack *stack, cfg fingerprintConfig) []string {
+	if rawStack == nil || cfg.frames <= 0 {
+		return nil
+	}
+
+	var frames []string
+	callFrames := runtime.CallersFrames(*rawStack)
+	for len(frames) < cfg.frames {
+		frame, more := callFrames.Next()
+		if frame.Function != "" && IsInAppFrame(frame, cfg.inAppPrefixes...) {
+			if cfg.ignoreLines {
+				frames = append(frames, frame.Function)
+			} else {
+				frames = append(frames, fmt.Sprintf("%s:%d", frame.Function, frame.Line))
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// IsInAppFrame reports whether the frame belongs to the application. The frames of the standard library (except the
+// main package) and the non-test code of this package are never in-app. If any prefixes are given, the function name
+// must also start with one of them.
+func IsInAppFrame(frame runtime.Frame, prefixes ...string) bool {
+	pkg := funcPackage(frame.Function)
+	if pkg != "main" && !strings.Contains(strings.SplitN(pkg, "/", 2)[0], ".") {
+		return false
+	}
+	if pkg == thisPackage && !strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// funcPackage returns the package path of the fully qualified function name, e.g. `github.com/a/b` for
+// `github.com/a/b.(*T).f`.
+func funcPackage(funcName string) string {
+	lastSlash := strings.LastIndex(funcName, "/")
+	if dot := strings.Index(funcName[lastSlash+1:], "."); dot >= 0 {
+		return funcName[:lastSlash+1+dot]
+	}
+	return funcName
+}
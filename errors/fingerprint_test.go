@@ -0,0 +1,95 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFingerprintError(id string) error {
+	return Wrap(New(fmt.Sprintf("project %q not found", id), Fields{"project_id": id}), "get project")
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, "", Fingerprint(nil))
+
+	var errs []error
+	for _, id := range []string{"p1", "p2"} {
+		errs = append(errs, newTestFingerprintError(id))
+	}
+	assert.Len(t, Fingerprint(errs[0]), 16)
+	assert.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+	assert.Equal(t, Fingerprint(errs[0]), Fingerprint(fmt.Errorf("std wrap: %w", errs[0])))
+
+	// Different call sites.
+	assert.NotEqual(t, Fingerprint(errs[0]), Fingerprint(newTestFingerprintError("p1")))
+	assert.Equal(t, Fingerprint(errs[0], WithFingerprintFrames(0)),
+		Fingerprint(newTestFingerprintError("p1"), WithFingerprintFrames(0)))
+
+	// Different message templates.
+	assert.NotEqual(t, Fingerprint(errs[0], WithFingerprintFrames(0)),
+		Fingerprint(Wrap(New("project not found"), "get project"), WithFingerprintFrames(0)))
+}
+
+func TestFingerprint_Codes(t *testing.T) {
+	err1 := WithCode(New("project p1 not found"), "NOT_FOUND")
+	err2 := WithCode(New("no such project: p2"), "NOT_FOUND")
+	assert.Equal(t, Fingerprint(err1, WithFingerprintFrames(0)), Fingerprint(err2, WithFingerprintFrames(0)))
+
+	awsErr1 := WithContext(awserr.New("Throttling", "rate exceeded", nil), "call")
+	awsErr2 := WithContext(awserr.New("AccessDenied", "rate exceeded", nil), "call")
+	assert.NotEqual(t, Fingerprint(awsErr1, WithFingerprintFrames(0)), Fingerprint(awsErr2, WithFingerprintFrames(0)))
+}
+
+func TestFingerprint_StandardError(t *testing.T) {
+	assert.Equal(t, Fingerprint(errors.New("timeout after 30s")), Fingerprint(errors.New("timeout after 5s")))
+	assert.NotEqual(t, Fingerprint(errors.New("timeout")), Fingerprint(errors.New("canceled")))
+}
+
+func TestFingerprint_LineNumbers(t *testing.T) {
+	var errs []error
+	for i := 0; i < 2; i++ {
+		if i == 0 {
+			errs = append(errs, New("error"))
+		} else {
+			errs = append(errs, New("error"))
+		}
+	}
+
+	assert.NotEqual(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+	assert.Equal(t, Fingerprint(errs[0], WithoutLineNumbers()), Fingerprint(errs[1], WithoutLineNumbers()))
+}
+
+func TestFingerprint_InAppFrames(t *testing.T) {
+	err := New("error").(NCError)
+	frames := inAppFrames(err.RawStack, fingerprintConfig{frames: 5, ignoreLines: true})
+	assert.Equal(t, []string{"github.com/nordcloud/ncerrors/errors.TestFingerprint_InAppFrames"}, frames)
+
+	frames = inAppFrames(err.RawStack, fingerprintConfig{frames: 5, inAppPrefixes: []string{"github.com/other/"}})
+	assert.Empty(t, frames)
+
+	assert.False(t, IsInAppFrame(runtime.Frame{Function: "testing.tRunner"}))
+	assert.False(t, IsInAppFrame(runtime.Frame{Function: thisPackage + ".New", File: "error.go"}))
+	assert.True(t, IsInAppFrame(runtime.Frame{Function: "main.main"}))
+	assert.True(t, IsInAppFrame(runtime.Frame{Function: "github.com/a/b.(*T).f"}, "github.com/a/"))
+	assert.False(t, IsInAppFrame(runtime.Frame{Function: "github.com/a/b.(*T).f"}, "github.com/c/"))
+}
+
+func TestMessageTemplate(t *testing.T) {
+	for message, want := range map[string]string{
+		`project "p1" not found`:                              `project <str> not found`,
+		"get arn:aws:s3:::bucket/key: access denied":          "get <arn>: access denied",
+		"user 5f2b1c3e-8d4a-4e6f-9b7c-1a2b3c4d5e6f not found": "user <uuid> not found",
+		"instance i-0abcdef1234567890 in 0x1f stopped":        "instance i-<hex> in <hex> stopped",
+		"retry 3 of 5 failed after 30s":                       "retry <num> of <num> failed after <num>s",
+		"no variable parts":                                   "no variable parts",
+	} {
+		assert.Equal(t, want, MessageTemplate(message), message)
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+const (
+	// TruncatedMarker is appended to the truncated field values.
+	TruncatedMarker = "...[truncated]"
+	// FieldsTruncatedKey holds the number of fields omitted because of FieldLimits.MaxTotalSize.
+	FieldsTruncatedKey = "fields_truncated"
+
+	depthExceededMarker = "..."
+	cycleMarker         = "<cycle>"
+)
+
+// DefaultFieldLimits are FieldLimits used unless changed with SetFieldLimits.
+var DefaultFieldLimits = FieldLimits{
+	MaxValueSize: 2048,
+	MaxTotalSize: 16384,
+	MaxDepth:     4,
+}
+
+var fieldLimits atomic.Pointer[FieldLimits]
+
+func init() {
+	SetFieldLimits(DefaultFieldLimits)
+}
+
+// FieldLimits bounds the size of the fields in the logs. Zero value of any limit means no limit.
+type FieldLimits struct {
+	// MaxValueSize is the maximal size in bytes of a single rendered field value. Longer values are truncated and
+	// marked with TruncatedMarker.
+	MaxValueSize int
+	// MaxTotalSize is the maximal size in bytes of the rendered keys and values of a single Fields. The fields are
+	// taken in the key order and the ones exceeding the limit are omitted. The number of omitted fields is stored
+	// under FieldsTruncatedKey.
+	MaxTotalSize int
+	// MaxDepth is the maximal depth of the rendered nested values (maps, slices, structs and pointers).
+	MaxDepth int
+}
+
+// SetFieldLimits sets FieldLimits used by all the logging functions and serializers of this package.
+func SetFieldLimits(limits FieldLimits) {
+	fieldLimits.Store(&limits)
+}
+
+// GetFieldLimits returns FieldLimits used by all the logging functions and serializers of this package.
+func GetFieldLimits() FieldLimits {
+	return *fieldLimits.Load()
+}
+
+// LimitFields returns a copy of fields bounded by the configured FieldLimits.
+func LimitFields(fields Fields) Fields {
+	return GetFieldLimits().Fields(fields)
+}
+
+// Fields returns a copy of fields bounded by the limits. Scalar values and nested values within the limits are kept
+// as they are, so structured log formats can still render them. Values exceeding the limits are replaced with their
+// truncated text rendering.
+func (l FieldLimits) Fields(fields Fields) Fields {
+	if fields == nil {
+		return nil
+	}
+
+	limited := make(Fields, len(fields))
+	keys := sortedKeys(fields)
+	total := 0
+	for i, k := range keys {
+		val, rendered := l.value(fields[k])
+		total += len(k) + len(rendered)
+		if l.MaxTotalSize > 0 && total > l.MaxTotalSize {
+			limited[FieldsTruncatedKey] = len(keys) - i
+			break
+		}
+		limited[k] = val
+	}
+
+	return limited
+}
+
+// Render returns the text rendering of the value bounded by the limits. Map keys are ordered, so the rendering is
+// deterministic.
+func (l FieldLimits) Render(val interface{}) string {
+	rendered, _ := l.render(val)
+	return rendered
+}
+
+// FormatFields renders fields as space separated `key=value` pairs ordered by key. The fields are redacted and bounded
+// by the configured Redactor and FieldLimits.
+func FormatFields(fields Fields) string {
+	limits := GetFieldLimits()
+	fields = limits.Fields(RedactFields(fields))
+
+	pairs := make([]string, 0, len(fields))
+	for _, k := range sortedKeys(fields) {
+		pairs = append(pairs, k+"="+limits.Render(fields[k]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Format implements fmt.Formatter. `%s` and `%v` print the error message and `%q` the quoted message. `%+v` prints
+// the message followed by every cause with its location and fields (see FormatFields) and the stack trace, with the
+// messages redacted by the configured Redactor.
+func (n NCError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('#') {
+			type plain NCError
+			fmt.Fprintf(s, "%#v", plain(n))
+			return
+		}
+		if s.Flag('+') {
+			n.formatVerbose(s)
+			return
+		}
+		_, _ = io.WriteString(s, n.Error())
+	case 's':
+		_, _ = io.WriteString(s, n.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", n.Error())
+	}
+}
+
+func (n NCError) formatVerbose(w io.Writer) {
+	_, _ = io.WriteString(w, RedactMessage(n.Error()))
+	for _, cause := range n.Causes {
+		_, _ = io.WriteString(w, "\n  - "+RedactMessage(cause.Message))
+		if cause.FuncName != "" {
+			fmt.Fprintf(w, " [%s:%d %s]", cause.FileName, cause.Line, cause.FuncName)
+		}
+		if len(cause.Fields) > 0 {
+			_, _ = io.WriteString(w, " "+FormatFields(cause.Fields))
+		}
+	}
+	if len(n.Stack) > 0 {
+		_, _ = io.WriteString(w, "\nstack:")
+		for _, frame := range n.Stack {
+			_, _ = io.WriteString(w, "\n  "+frame)
+		}
+	}
+}
+
+// limitNCError returns a copy of ncError with the causes' fields bounded by the configured FieldLimits.
+func limitNCError(ncError NCError) NCError {
+	limits := GetFieldLimits()
+	causes := make([]Cause, len(ncError.Causes))
+	for i, cause := range ncError.Causes {
+		cause.Fields = limits.Fields(cause.Fields)
+		causes[i] = cause
+	}
+	ncError.Causes = causes
+	return ncError
+}
+
+// value returns the value bounded by the limits and its text rendering.
+func (l FieldLimits) value(val interface{}) (interface{}, string) {
+	switch v := val.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return val, fmt.Sprint(v)
+	case string:
+		truncated := l.truncate(v)
+		return truncated, truncated
+	}
+
+	rendered, complete := l.render(val)
+	if complete {
+		return val, rendered
+	}
+	return rendered, rendered
+}
+
+// render returns the text rendering of the value and false if it was truncated.
+func (l FieldLimits) render(val interface{}) (string, bool) {
+	var b strings.Builder
+	complete := l.writeValue(&b, reflect.ValueOf(val), 0, map[visitKey]bool{})
+	rendered := l.truncate(b.String())
+	return rendered, complete && len(rendered) == b.Len()
+}
+
+// truncate cuts s to MaxValueSize without splitting the last UTF-8 character. Invalid UTF-8 elsewhere in s is kept as
+// it is.
+func (l FieldLimits) truncate(s string) string {
+	if l.MaxValueSize <= 0 || len(s) <= l.MaxValueSize {
+		return s
+	}
+
+	s = s[:l.MaxValueSize]
+	// Drop the incomplete trailing rune, its start byte is among the last utf8.UTFMax-1 bytes.
+	for i := len(s) - 1; i >= 0 && i >= len(s)-(utf8.UTFMax-1); i-- {
+		if utf8.RuneStart(s[i]) {
+			if !utf8.FullRuneInString(s[i:]) {
+				s = s[:i]
+			}
+			break
+		}
+	}
+	return s + TruncatedMarker
+}
+
+// visitKey identifies the pointer, map or slice being rendered.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// writeValue writes the text rendering of the value to b. It returns false if the value was cut at MaxDepth or at
+// a cycle. The visited holds the references on the path to the value, so the cycles are detected even without
+// MaxDepth.
+func (l FieldLimits) writeValue(b *strings.Builder, v reflect.Value, depth int, visited map[visitKey]bool) bool {
+	if !v.IsValid() || (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		b.WriteString("<nil>")
+		return true
+	}
+	if v.CanInterface() {
+		switch i := v.Interface().(type) {
+		case error:
+			b.WriteString(i.Error())
+			return true
+		case fmt.Stringer:
+			b.WriteString(i.String())
+			return true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.Kind() != reflect.Slice || v.Len() > 0 {
+			key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+			if visited[key] {
+				b.WriteString(cycleMarker)
+				return false
+			}
+			visited[key] = true
+			defer delete(visited, key)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return l.writeValue(b, v.Elem(), depth, visited)
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if l.MaxDepth > 0 && depth >= l.MaxDepth {
+			b.WriteString(depthExceededMarker)
+			return false
+		}
+	case reflect.String:
+		b.WriteString(v.String())
+		return true
+	default:
+		fmt.Fprint(b, v)
+		return true
+	}
+
+	complete := true
+	switch v.Kind() {
+	case reflect.Map:
+		keys := v.MapKeys()
+		renderedKeys := make([]string, len(keys))
+		for i, key := range keys {
+			renderedKeys[i] = fmt.Sprint(key)
+		}
+		sort.Sort(byRenderedKey{keys: keys, rendered: renderedKeys})
+
+		b.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(renderedKeys[i] + ": ")
+			complete = l.writeValue(b, v.MapIndex(key), depth+1, visited) && complete
+		}
+		b.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			complete = l.writeValue(b, v.Index(i), depth+1, visited) && complete
+		}
+		b.WriteString("]")
+	case reflect.Struct:
+		b.WriteString("{")
+		written := 0
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if written > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(field.Name + ": ")
+			complete = l.writeValue(b, v.Field(i), depth+1, visited) && complete
+			written++
+		}
+		b.WriteString("}")
+	}
+
+	return complete
+}
+
+type byRenderedKey struct {
+	keys     []reflect.Value
+	rendered []string
+}
+
+func (k byRenderedKey) Len() int           { return len(k.keys) }
+func (k byRenderedKey) Less(i, j int) bool { return k.rendered[i] < k.rendered[j] }
+func (k byRenderedKey) Swap(i, j int) {
+	k.keys[i], k.keys[j] = k.keys[j], k.keys[i]
+	k.rendered[i], k.rendered[j] = k.rendered[j], k.rendered[i]
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRenderStruct struct {
+	Name    string
+	Nested  *testRenderStruct
+	private int
+}
+
+func TestFieldLimits_Render(t *testing.T) {
+	limits := FieldLimits{MaxDepth: 2}
+
+	assert.Equal(t, "{a: 1, b: [x, y], c: <nil>}", limits.Render(map[string]interface{}{"c": nil, "b": []string{"x", "y"}, "a": 1}))
+	assert.Equal(t, "{Name: n1, Nested: {Name: n2, Nested: ...}}",
+		limits.Render(testRenderStruct{Name: "n1", Nested: &testRenderStruct{Name: "n2", Nested: &testRenderStruct{}}}))
+	assert.Equal(t, "[std, <nil>]", limits.Render([]interface{}{errors.New("std"), (*testRenderStruct)(nil)}))
+	assert.Equal(t, RedactedValue, limits.Render(Secret("pass")))
+}
+
+func TestFieldLimits_Fields(t *testing.T) {
+	limits := FieldLimits{MaxValueSize: 10, MaxDepth: 1}
+	nested := map[string]interface{}{"a": map[string]int{"b": 1}}
+
+	assert.Nil(t, limits.Fields(nil))
+	assert.Equal(t, Fields{
+		"a_long":   "0123456789" + TruncatedMarker,
+		"b_nested": "{a: ...}",
+		"c_slice":  []int{1, 2},
+		"d_int":    1,
+	}, limits.Fields(Fields{
+		"a_long":   "0123456789abc",
+		"b_nested": nested,
+		"c_slice":  []int{1, 2},
+		"d_int":    1,
+	}))
+
+	limits.MaxTotalSize = 60
+	assert.Equal(t, Fields{
+		"a_long":           "0123456789" + TruncatedMarker,
+		"b_long":           "0123456789" + TruncatedMarker,
+		FieldsTruncatedKey: 2,
+	}, limits.Fields(Fields{
+		"a_long": strings.Repeat("0123456789", 3),
+		"b_long": strings.Repeat("0123456789", 3),
+		"c_long": strings.Repeat("0123456789", 3),
+		"d_int":  1,
+	}))
+}
+
+func TestFormatFields(t *testing.T) {
+	assert.Equal(t, "a=1 b=[x, y] c={k: v} password=[REDACTED]", FormatFields(Fields{
+		"password": "pass",
+		"c":        map[string]string{"k": "v"},
+		"b":        []string{"x", "y"},
+		"a":        1,
+	}))
+}
+
+func TestNCErrorFormat(t *testing.T) {
+	err := WithContext(errors.New("root"), "level1", Fields{"b": 2, "a": "1", "token": "t"})
+
+	assert.Equal(t, "level1: root", fmt.Sprintf("%v", err))
+	assert.Equal(t, "level1: root", fmt.Sprintf("%s", err))
+	assert.Equal(t, `"level1: root"`, fmt.Sprintf("%q", err))
+
+	verbose := fmt.Sprintf("%+v", err)
+	stack := err.(NCError).Stack
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/error.go(WithContext)", frameNames(stack)[0])
+	assert.True(t, strings.HasPrefix(verbose, "level1: root\n"+
+		"  - level1 [github.com/nordcloud/ncerrors/errors/format_test.go:70 TestNCErrorFormat] a=1 b=2 token=[REDACTED]\n"+
+		"  - root\n"+
+		"stack:\n"+
+		"  "+stack[0]+"\n"+
+		"  github.com/nordcloud/ncerrors/errors/format_test.go(TestNCErrorFormat):70"), verbose)
+}
+
+func TestLimits_LogFields(t *testing.T) {
+	defer SetFieldLimits(DefaultFieldLimits)
+	SetFieldLimits(FieldLimits{MaxValueSize: 3})
+
+	err := New("error1", Fields{"a": "0123"})
+	assert.Equal(t, "012"+TruncatedMarker, buildPlainLogFields(err)["a"])
+	assert.Equal(t, "0123", err.(NCError).Causes[0].Fields["a"])
+}
+
+func TestFieldLimits_RenderCycle(t *testing.T) {
+	cyclic := &testRenderStruct{Name: "n1"}
+	cyclic.Nested = &testRenderStruct{Name: "n2", Nested: cyclic}
+	shared := &testRenderStruct{Name: "s"}
+	cyclicMap := map[string]interface{}{"a": 1}
+	cyclicMap["self"] = cyclicMap
+
+	limits := FieldLimits{}
+	assert.Equal(t, "{Name: n1, Nested: {Name: n2, Nested: <cycle>}}", limits.Render(cyclic))
+	assert.Equal(t, "[{Name: s, Nested: <nil>}, {Name: s, Nested: <nil>}]",
+		limits.Render([]*testRenderStruct{shared, shared}))
+	assert.Equal(t, "{a: 1, self: <cycle>}", limits.Render(cyclicMap))
+	assert.Equal(t, Fields{"a": "{Name: n1, Nested: {Name: n2, Nested: <cycle>}}"}, limits.Fields(Fields{"a": cyclic}))
+}
+
+func TestFieldLimits_TruncateUTF8(t *testing.T) {
+	limits := FieldLimits{MaxValueSize: 5}
+
+	assert.Equal(t, "aaaa"+TruncatedMarker, limits.truncate("aaaaé"))
+	assert.Equal(t, "aaaé"+TruncatedMarker, limits.truncate("aaaéb"))
+	assert.Equal(t, "€"+TruncatedMarker, limits.truncate("€€"))
+	assert.Equal(t, "aaaaé", FieldLimits{MaxValueSize: 6}.truncate("aaaaé"))
+	// Invalid UTF-8 is kept.
+	assert.Equal(t, "a\xffbcd"+TruncatedMarker, limits.truncate("a\xffbcdef"))
+}
@@ -0,0 +1,249 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package httperrors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nordcloud/ncerrors/errors"
+)
+
+// Fields of the response errors.
+const (
+	KeyHTTPURL          = "http_url"
+	KeyHTTPStatus       = "http_status"
+	KeyHTTPResponseBody = "http_response_body"
+	KeyRemoteRequestID  = "remote_request_id"
+	// KeyRemoteDetail holds the problem details' detail or the ErrorResponse hint sent by the server.
+	KeyRemoteDetail = "remote_detail"
+)
+
+// DefaultBodyLimit is the default number of the response body bytes read by FromResponse.
+const DefaultBodyLimit = 4096
+
+// truncatedMarker is appended to the truncated body excerpts.
+const truncatedMarker = "..."
+
+// knownKinds are the kinds accepted from the servers, other kinds are replaced by the kind of the status.
+var knownKinds = map[errors.Kind]bool{
+	errors.KindInternal:         true,
+	errors.KindInvalidArgument:  true,
+	errors.KindNotFound:         true,
+	errors.KindConflict:         true,
+	errors.KindPermissionDenied: true,
+	errors.KindUnauthenticated:  true,
+	errors.KindRateLimited:      true,
+	errors.KindUnavailable:      true,
+	errors.KindTimeout:          true,
+	errors.KindCanceled:         true,
+}
+
+// ResponseOption configures FromResponse and Transport.
+type ResponseOption func(cfg *responseConfig)
+
+type responseConfig struct {
+	bodyLimit       int
+	requestIDHeader string
+}
+
+// WithBodyLimit sets the number of the response body bytes read by FromResponse, DefaultBodyLimit by default. The
+// body is decoded and added as the excerpt only up to the limit.
+func WithBodyLimit(limit int) ResponseOption {
+	return func(cfg *responseConfig) {
+		cfg.bodyLimit = limit
+	}
+}
+
+// WithRemoteRequestIDHeader sets the response header the remote request ID is taken from, DefaultRequestIDHeader by
+// default.
+func WithRemoteRequestIDHeader(header string) ResponseOption {
+	return func(cfg *responseConfig) {
+		cfg.requestIDHeader = header
+	}
+}
+
+func newResponseConfig(opts []ResponseOption) responseConfig {
+	cfg := responseConfig{
+		bodyLimit:       DefaultBodyLimit,
+		requestIDHeader: DefaultRequestIDHeader,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// FromResponse converts the non-2xx response into NCError. It returns nil for the 2xx responses, otherwise it reads
+// up to the body limit and closes the response body. The error has the request method, the URL (with the query values
+// and the password redacted), the status, the body excerpt and the remote request ID as the fields.
+//
+// The problem details (see errors.ProblemDetails) and ErrorResponse bodies are decoded: the remote message is added
+// to the error message, the remote detail (or hint) is added as KeyRemoteDetail field and the remote code and kind
+// are set on the error. The kind is derived from the status if the server sent none or an unknown one.
+func FromResponse(resp *http.Response, opts ...ResponseOption) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	cfg := newResponseConfig(opts)
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, int64(cfg.bodyLimit)+1))
+	_ = resp.Body.Close()
+
+	fields := errors.Fields{KeyHTTPStatus: resp.StatusCode}
+	if resp.Request != nil {
+		fields[KeyHTTPMethod] = resp.Request.Method
+		fields[KeyHTTPURL] = RedactURL(resp.Request.URL)
+	}
+	if requestID := resp.Header.Get(cfg.requestIDHeader); requestID != "" {
+		fields[KeyRemoteRequestID] = requestID
+	}
+	if len(body) > 0 {
+		fields[KeyHTTPResponseBody] = bodyExcerpt(body, cfg.bodyLimit)
+	}
+
+	remote := decodeBody(resp.Header.Get("Content-Type"), body)
+	if remote.Hint != "" {
+		fields[KeyRemoteDetail] = remote.Hint
+	}
+	message := fmt.Sprintf("unexpected HTTP status: %s", resp.Status)
+	if remote.Message != "" {
+		message += ": " + remote.Message
+	}
+
+	err := errors.New(message, fields)
+	if remote.Code != "" {
+		err = errors.WithCode(err, remote.Code)
+	}
+	if !knownKinds[remote.Kind] {
+		remote.Kind = statusKind(resp.StatusCode)
+	}
+	return errors.WithKind(err, remote.Kind)
+}
+
+// Transport is http.RoundTripper returning the 4xx and 5xx responses as the errors, see FromResponse. The http.Client
+// wraps the errors into *url.Error, use errors.AsNC to get NCError. The 1xx, 2xx and 3xx responses are returned as
+// they are, so http.Client still follows the redirects and the callers see 304 Not Modified.
+//
+// Note that http.RoundTripper should not return an error for the response status. Transport does it deliberately for
+// the error statuses, so the callers can't read the error response themselves (its body is consumed and closed).
+// Use FromResponse with the default transport where the error responses need to be inspected.
+type Transport struct {
+	// Base is the underlying http.RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+	// Options are passed to FromResponse.
+	Options []ResponseOption
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 400 {
+		return resp, nil
+	}
+	if err := FromResponse(resp, t.Options...); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RedactURL returns the URL with the query values and the password replaced by errors.RedactedValue.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+
+	redacted := *u
+	if redacted.RawQuery != "" {
+		query := redacted.Query()
+		for key := range query {
+			query[key] = []string{errors.RedactedValue}
+		}
+		redacted.RawQuery = query.Encode()
+	}
+	if _, ok := redacted.User.Password(); ok {
+		redacted.User = url.UserPassword(redacted.User.Username(), errors.RedactedValue)
+	}
+	return redacted.String()
+}
+
+// decodeBody decodes the problem details or ErrorResponse body. It returns the zero ErrorBody if the body is neither.
+func decodeBody(contentType string, body []byte) ErrorBody {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == errors.ProblemJSONContentType:
+		var problem errors.ProblemDetails
+		if json.Unmarshal(body, &problem) != nil {
+			return ErrorBody{}
+		}
+		return ErrorBody{Code: problem.Code, Message: problem.Title, Hint: problem.Detail}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		var resp ErrorResponse
+		if json.Unmarshal(body, &resp) != nil {
+			return ErrorBody{}
+		}
+		return resp.Error
+	default:
+		return ErrorBody{}
+	}
+}
+
+// bodyExcerpt returns the body cut to the limit without splitting the last UTF-8 character. Invalid UTF-8 elsewhere
+// in the body is kept as it is.
+func bodyExcerpt(body []byte, limit int) string {
+	if len(body) <= limit {
+		return string(body)
+	}
+
+	body = body[:limit]
+	// Drop the incomplete trailing rune, its start byte is among the last utf8.UTFMax-1 bytes.
+	for i := len(body) - 1; i >= 0 && i >= len(body)-(utf8.UTFMax-1); i-- {
+		if utf8.RuneStart(body[i]) {
+			if !utf8.FullRune(body[i:]) {
+				body = body[:i]
+			}
+			break
+		}
+	}
+	return string(body) + truncatedMarker
+}
+
+// statusKind returns the error kind of the HTTP status, the inverse of errors.KindHTTPStatus.
+func statusKind(status int) errors.Kind {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errors.KindInvalidArgument
+	case http.StatusUnauthorized:
+		return errors.KindUnauthenticated
+	case http.StatusForbidden:
+		return errors.KindPermissionDenied
+	case http.StatusNotFound:
+		return errors.KindNotFound
+	case http.StatusConflict:
+		return errors.KindConflict
+	case http.StatusTooManyRequests:
+		return errors.KindRateLimited
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return errors.KindUnavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return errors.KindTimeout
+	case errors.StatusClientClosedRequest:
+		return errors.KindCanceled
+	default:
+		return errors.KindInternal
+	}
+}
@@ -0,0 +1,292 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package httperrors provides net/http helpers for NCError: the server middleware writing the error responses and the
+// client helpers converting the error responses into NCErrors.
+package httperrors
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/nordcloud/ncerrors/errors"
+)
+
+// Fields of the request errors.
+const (
+	KeyHTTPMethod = "http_method"
+	KeyHTTPPath   = "http_path"
+)
+
+// DefaultRequestIDHeader is the header the request ID is taken from by default.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// HandlerFunc is the HTTP handler returning the error. Use Handler to adapt it to http.Handler.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ResponseFormat writes the error response. The request fields are already added to the error.
+type ResponseFormat func(w http.ResponseWriter, r *http.Request, err error)
+
+// Option configures Handler and Middleware.
+type Option func(cfg *config)
+
+type config struct {
+	format          ResponseFormat
+	requestIDHeader string
+	log             func(err error)
+}
+
+// WithResponseFormat sets the format of the error responses, ProblemJSON by default.
+func WithResponseFormat(format ResponseFormat) Option {
+	return func(cfg *config) {
+		cfg.format = format
+	}
+}
+
+// WithRequestIDHeader sets the header the request ID is taken from, DefaultRequestIDHeader by default.
+func WithRequestIDHeader(header string) Option {
+	return func(cfg *config) {
+		cfg.requestIDHeader = header
+	}
+}
+
+// WithLogger sets the function logging the errors, errors.LogWithSeverity by default.
+func WithLogger(log func(err error)) Option {
+	return func(cfg *config) {
+		cfg.log = log
+	}
+}
+
+func newConfig(opts []Option) config {
+	cfg := config{
+		format:          ProblemJSON,
+		requestIDHeader: DefaultRequestIDHeader,
+		log:             errors.LogWithSeverity,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Handler adapts the handler returning the error to http.Handler. The request method, path and ID are attached to
+// the request context with errors.ContextWithFields and added to the returned error. The panics are recovered into
+// NCErrors (except http.ErrAbortHandler). The errors are logged and written as the responses with the status derived
+// from the error (see errors.HTTPStatus), unless the handler has already written the response headers.
+func Handler(fn HandlerFunc, opts ...Option) http.Handler {
+	cfg := newConfig(opts)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serve(cfg, w, r, fn)
+	})
+}
+
+// Middleware returns the middleware recovering the panics of the next handler like Handler does. The request fields
+// are attached to the request context.
+func Middleware(opts ...Option) func(next http.Handler) http.Handler {
+	cfg := newConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			serve(cfg, w, r, func(w http.ResponseWriter, r *http.Request) error {
+				next.ServeHTTP(w, r)
+				return nil
+			})
+		})
+	}
+}
+
+// ErrorResponse is the JSON error response, see JSON.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes the error in ErrorResponse. It contains only the user-facing information.
+type ErrorBody struct {
+	// Code is the error code, see errors.GetCode.
+	Code string `json:"code,omitempty"`
+	// Kind is the error kind, see errors.GetKind.
+	Kind errors.Kind `json:"kind"`
+	// Message is the user-facing message of the error, see errors.PublicMessage.
+	Message string `json:"message"`
+	// Hint is the user-facing remediation hint of the error, see errors.PublicHint.
+	Hint string `json:"hint,omitempty"`
+	// RequestID is the ID of the failed request.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ProblemJSON writes the error as the problem details (see errors.GetProblemDetails) with the request path as the
+// instance.
+func ProblemJSON(w http.ResponseWriter, r *http.Request, err error) {
+	problem := errors.GetProblemDetails(err)
+	problem.Instance = r.URL.Path
+	writeJSON(w, errors.ProblemJSONContentType, problem.Status, problem)
+}
+
+// JSON writes the error as ErrorResponse.
+func JSON(w http.ResponseWriter, r *http.Request, err error) {
+	requestID, _ := errors.GetField[string](err, errors.KeyRequestID)
+	writeJSON(w, "application/json", errors.HTTPStatus(err), ErrorResponse{Error: ErrorBody{
+		Code:      errors.GetCode(err),
+		Kind:      errors.GetKind(err),
+		Message:   errors.PublicMessage(err),
+		Hint:      errors.PublicHint(err),
+		RequestID: requestID,
+	}})
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func serve(cfg config, w http.ResponseWriter, r *http.Request, fn HandlerFunc) {
+	fields := requestFields(cfg, r)
+	r = r.WithContext(errors.ContextWithFields(r.Context(), fields))
+	w, rw := wrapResponseWriter(w)
+
+	defer func() {
+		var err error
+		if rec := recover(); rec != nil {
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			err = errors.FromPanic(rec)
+		}
+		if err == nil {
+			return
+		}
+		handleError(cfg, rw, r, errors.WithDefaultFields(err, fields))
+	}()
+
+	if err := fn(w, r); err != nil {
+		handleError(cfg, rw, r, errors.WithDefaultFields(err, fields))
+	}
+}
+
+func handleError(cfg config, rw *responseWriter, r *http.Request, err error) {
+	cfg.log(err)
+	if !rw.wroteHeader {
+		cfg.format(rw, r, err)
+	}
+}
+
+func requestFields(cfg config, r *http.Request) errors.Fields {
+	fields := errors.Fields{
+		KeyHTTPMethod: r.Method,
+		KeyHTTPPath:   r.URL.Path,
+	}
+	if requestID := r.Header.Get(cfg.requestIDHeader); requestID != "" {
+		fields[errors.KeyRequestID] = requestID
+	}
+	return fields
+}
+
+// responseWriter records whether the response headers were written.
+type responseWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// wrapResponseWriter returns the http.ResponseWriter wrapping w which implements the same optional interfaces
+// (http.Flusher, http.Hijacker and io.ReaderFrom) as w, so the handlers can still type-assert them.
+func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWriter) {
+	rw := &responseWriter{ResponseWriter: w}
+	_, isFlusher := w.(http.Flusher)
+	_, isHijacker := w.(http.Hijacker)
+	_, isReaderFrom := w.(io.ReaderFrom)
+
+	f, h, rf := flushWriter{rw}, hijackWriter{rw}, readerFromWriter{rw}
+	switch {
+	case isFlusher && isHijacker && isReaderFrom:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, f, h, rf}, rw
+	case isFlusher && isHijacker:
+		return struct {
+			*responseWriter
+			http.Flusher
+			http.Hijacker
+		}{rw, f, h}, rw
+	case isFlusher && isReaderFrom:
+		return struct {
+			*responseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{rw, f, rf}, rw
+	case isHijacker && isReaderFrom:
+		return struct {
+			*responseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, h, rf}, rw
+	case isFlusher:
+		return struct {
+			*responseWriter
+			http.Flusher
+		}{rw, f}, rw
+	case isHijacker:
+		return struct {
+			*responseWriter
+			http.Hijacker
+		}{rw, h}, rw
+	case isReaderFrom:
+		return struct {
+			*responseWriter
+			io.ReaderFrom
+		}{rw, rf}, rw
+	default:
+		return rw, rw
+	}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(data)
+}
+
+// Unwrap returns the original http.ResponseWriter for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+type flushWriter struct{ w *responseWriter }
+
+func (f flushWriter) Flush() {
+	f.w.wroteHeader = true
+	f.w.ResponseWriter.(http.Flusher).Flush()
+}
+
+type hijackWriter struct{ w *responseWriter }
+
+func (h hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := h.w.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil {
+		// The connection is taken over, so the error response can't be written anymore.
+		h.w.wroteHeader = true
+	}
+	return conn, buf, err
+}
+
+type readerFromWriter struct{ w *responseWriter }
+
+func (rf readerFromWriter) ReadFrom(src io.Reader) (int64, error) {
+	rf.w.wroteHeader = true
+	return rf.w.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+}
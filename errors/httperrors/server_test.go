@@ -0,0 +1,190 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package httperrors
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	hook := test.NewGlobal()
+
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/missing":
+			return errors.WithPublicMessage(
+				errors.WithKind(errors.NewCtx(r.Context(), "db row not found"), errors.KindNotFound),
+				"The project does not exist.")
+		case "/written":
+			w.WriteHeader(http.StatusAccepted)
+			return errors.New("failed after write")
+		default:
+			_, _ = w.Write([]byte("ok"))
+			return nil
+		}
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+	assert.Empty(t, hook.AllEntries())
+
+	req := httptest.NewRequest(http.MethodGet, "/missing?id=1", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req1")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, errors.ProblemJSONContentType, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"title": "The project does not exist.", "status": 404, "instance": "/missing"}`,
+		rec.Body.String())
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "db row not found", entry.Message)
+	causes := entry.Data["error_context"].(errors.Fields)["causes"].([]errors.Cause)
+	assert.Equal(t, errors.Fields{KeyHTTPMethod: "GET", KeyHTTPPath: "/missing", errors.KeyRequestID: "req1"},
+		causes[0].Fields)
+
+	hook.Reset()
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/written", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, "failed after write", hook.LastEntry().Message)
+}
+
+func TestHandler_JSON(t *testing.T) {
+	test.NewGlobal()
+
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.WithKind(errors.New("invalid name"), errors.KindInvalidArgument)
+	}, WithResponseFormat(JSON), WithRequestIDHeader("X-Amzn-Trace-Id"))
+
+	req := httptest.NewRequest(http.MethodPut, "/projects", nil)
+	req.Header.Set("X-Amzn-Trace-Id", "trace1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t,
+		`{"error": {"kind": "invalid_argument", "message": "The request is invalid.", "request_id": "trace1"}}`,
+		rec.Body.String())
+}
+
+func TestMiddleware(t *testing.T) {
+	test.NewGlobal()
+
+	var logged error
+	handler := Middleware(WithLogger(func(err error) { logged = err }))(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/boom", errors.FieldsFromContext(r.Context())[KeyHTTPPath])
+			panic("boom")
+		}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, errors.ProblemJSONContentType, rec.Header().Get("Content-Type"))
+	assert.JSONEq(t,
+		`{"title": "An internal error occurred. Please try again later.", "status": 500, "instance": "/boom"}`,
+		rec.Body.String())
+
+	assert.EqualError(t, logged, "panic: boom")
+	assert.Equal(t, errors.ERROR, errors.GetErrorSeverity(logged))
+	method, _ := errors.GetField[string](logged, KeyHTTPMethod)
+	assert.Equal(t, "GET", method)
+}
+
+func TestMiddleware_AbortHandler(t *testing.T) {
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestMiddleware_PanicAfterWrite(t *testing.T) {
+	hook := test.NewGlobal()
+
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("partial"))
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "partial", rec.Body.String())
+	assert.Equal(t, "panic: boom", hook.LastEntry().Message)
+}
+
+func TestMiddleware_ResponseWriterInterfaces(t *testing.T) {
+	test.NewGlobal()
+
+	var flusher, hijacker, readerFrom bool
+	handler := Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, flusher = w.(http.Flusher)
+		_, hijacker = w.(http.Hijacker)
+		_, readerFrom = w.(io.ReaderFrom)
+		if r.URL.Path == "/hijack" {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			assert.NoError(t, err)
+			_, _ = buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+			_ = buf.Flush()
+			_ = conn.Close()
+			panic("after hijack")
+		}
+		w.(http.Flusher).Flush()
+	}))
+
+	// httptest.ResponseRecorder implements http.Flusher only.
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.True(t, flusher)
+	assert.False(t, hijacker)
+	assert.False(t, readerFrom)
+	assert.True(t, rec.Flushed)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.True(t, flusher)
+	assert.True(t, hijacker)
+	assert.True(t, readerFrom)
+
+	resp, err = http.Get(server.URL + "/hijack")
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, "hijacked", string(body))
+}
+
+func TestHandler_KeepsErrorFields(t *testing.T) {
+	hook := test.NewGlobal()
+
+	handler := Handler(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("failed", errors.Fields{KeyHTTPPath: "/projects/{id}", errors.KeyRequestID: "upstream-req"})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/projects/1", nil)
+	req.Header.Set(DefaultRequestIDHeader, "req1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	causes := hook.LastEntry().Data["error_context"].(errors.Fields)["causes"].([]errors.Cause)
+	assert.Equal(t, errors.Fields{KeyHTTPMethod: "GET", KeyHTTPPath: "/projects/{id}", errors.KeyRequestID: "upstream-req"},
+		causes[0].Fields)
+}
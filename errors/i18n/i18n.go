@@ -0,0 +1,220 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package i18n localises the user-facing messages of NCError.
+//
+// The messages are Go text/template templates keyed by the error code (see errors.WithCode). The templates are
+// executed with the merged (and redacted) error fields, e.g. `Project {{.project_id}} was not found.`
+package i18n
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+var defaultLocalizer atomic.Pointer[Localizer]
+
+func init() {
+	SetCatalog(MapCatalog{})
+}
+
+// Catalog provides the message templates keyed by the error code.
+type Catalog interface {
+	// Languages returns the languages of the catalog.
+	Languages() []language.Tag
+	// Template returns the message template of the code in the language.
+	Template(tag language.Tag, code string) (string, bool)
+}
+
+// MapCatalog is Catalog keeping the templates in memory, by language and code.
+type MapCatalog map[language.Tag]map[string]string
+
+// Languages returns the languages of the catalog ordered by their names.
+func (c MapCatalog) Languages() []language.Tag {
+	tags := make([]language.Tag, 0, len(c))
+	for tag := range c {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].String() < tags[j].String()
+	})
+	return tags
+}
+
+// Template returns the message template of the code in the language.
+func (c MapCatalog) Template(tag language.Tag, code string) (string, bool) {
+	tmpl, ok := c[tag][code]
+	return tmpl, ok
+}
+
+// LoadDir loads MapCatalog from the files in the directory, see LoadFS.
+func LoadDir(dir string) (MapCatalog, error) {
+	return LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS loads MapCatalog from the files `<locale>.json`, `<locale>.yaml` or `<locale>.yml` in the directory of fsys,
+// e.g. `en.yaml` or `fi-FI.json`. Every file maps the error codes to the message templates. Other files are ignored.
+func LoadFS(fsys fs.FS, dir string) (MapCatalog, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read catalog directory", errors.Fields{"dir": dir})
+	}
+
+	catalog := MapCatalog{}
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		fields := errors.Fields{"file": entry.Name()}
+		tag, err := language.Parse(strings.TrimSuffix(entry.Name(), ext))
+		if err != nil {
+			return nil, errors.Wrap(err, "parse catalog locale", fields)
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "read catalog file", fields)
+		}
+
+		templates := map[string]string{}
+		if ext == ".json" {
+			err = json.Unmarshal(data, &templates)
+		} else {
+			err = yaml.Unmarshal(data, &templates)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "decode catalog file", fields)
+		}
+
+		if catalog[tag] == nil {
+			catalog[tag] = map[string]string{}
+		}
+		for code, tmpl := range templates {
+			catalog[tag][code] = tmpl
+		}
+	}
+
+	return catalog, nil
+}
+
+// Localizer localises the user-facing messages using Catalog. The templates are parsed once and cached.
+type Localizer struct {
+	catalog Catalog
+	tags    []language.Tag
+	matcher language.Matcher
+	// templates caches the parsed templates by their source, nil for the templates failing to parse.
+	templates sync.Map
+}
+
+// NewLocalizer returns Localizer for the catalog. English is the fallback language. The templates of MapCatalog are
+// parsed here, the templates of other catalogs when they are used for the first time.
+func NewLocalizer(catalog Catalog) *Localizer {
+	tags := []language.Tag{language.English}
+	for _, tag := range catalog.Languages() {
+		if tag != language.English {
+			tags = append(tags, tag)
+		}
+	}
+
+	l := &Localizer{
+		catalog: catalog,
+		tags:    tags,
+		matcher: language.NewMatcher(tags),
+	}
+	if mapCatalog, ok := catalog.(MapCatalog); ok {
+		for _, templates := range mapCatalog {
+			for _, tmpl := range templates {
+				l.parse(tmpl)
+			}
+		}
+	}
+	return l
+}
+
+// Localize returns the user-facing message of the error in the language best matching lang, which is either
+// a language tag or an Accept-Language header value. The template of the error code is looked up in the matched
+// language and then in English. If there is none (or it fails to execute) errors.PublicMessage is returned.
+func (l *Localizer) Localize(err error, lang string) string {
+	code := errors.GetCode(err)
+	if code == "" {
+		return errors.PublicMessage(err)
+	}
+
+	params := errors.Fields{}
+	if ncError, ok := errors.AsNC(err); ok {
+		params = errors.RedactFields(ncError.GetMergedFields())
+	}
+
+	for _, tag := range l.candidates(lang) {
+		tmpl, ok := l.catalog.Template(tag, code)
+		if !ok {
+			continue
+		}
+		if message, ok := execute(l.parse(tmpl), params); ok {
+			return message
+		}
+	}
+
+	return errors.PublicMessage(err)
+}
+
+func (l *Localizer) candidates(lang string) []language.Tag {
+	desired, _, err := language.ParseAcceptLanguage(lang)
+	if err != nil || len(desired) == 0 {
+		return []language.Tag{language.English}
+	}
+
+	_, idx, _ := l.matcher.Match(desired...)
+	if l.tags[idx] == language.English {
+		return []language.Tag{language.English}
+	}
+	return []language.Tag{l.tags[idx], language.English}
+}
+
+// parse returns the parsed template of the source, nil if it fails to parse.
+func (l *Localizer) parse(source string) *template.Template {
+	if t, ok := l.templates.Load(source); ok {
+		return t.(*template.Template)
+	}
+
+	t, err := template.New("message").Option("missingkey=error").Parse(source)
+	if err != nil {
+		t = nil
+	}
+	l.templates.Store(source, t)
+	return t
+}
+
+func execute(t *template.Template, params errors.Fields) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	if err := t.Execute(&b, map[string]interface{}(params)); err != nil {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// SetCatalog sets Catalog used by Localize.
+func SetCatalog(catalog Catalog) {
+	defaultLocalizer.Store(NewLocalizer(catalog))
+}
+
+// Localize returns the user-facing message of the error in the language best matching lang using Catalog set with
+// SetCatalog. See Localizer.Localize.
+func Localize(err error, lang string) string {
+	return defaultLocalizer.Load().Localize(err, lang)
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package i18n
+
+import (
+	stderrors "errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestLoadDir(t *testing.T) {
+	catalog, err := LoadDir("testdata")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []language.Tag{language.MustParse("de-DE"), language.English, language.Finnish}, catalog.Languages())
+	tmpl, ok := catalog.Template(language.Finnish, "PROJECT_NOT_FOUND")
+	assert.True(t, ok)
+	assert.Equal(t, "Projektia {{.project_id}} ei löytynyt.", tmpl)
+	_, ok = catalog.Template(language.Finnish, "QUOTA_EXCEEDED")
+	assert.False(t, ok)
+}
+
+func TestLoadFS_Errors(t *testing.T) {
+	_, err := LoadFS(fstest.MapFS{"invalid_locale.json": {Data: []byte("{}")}}, ".")
+	assert.Error(t, err)
+
+	_, err = LoadFS(fstest.MapFS{"en.json": {Data: []byte("[")}}, ".")
+	assert.Error(t, err)
+	file, _ := errors.GetField[string](err, "file")
+	assert.Equal(t, "en.json", file)
+}
+
+func TestLocalize(t *testing.T) {
+	catalog, err := LoadDir("testdata")
+	assert.NoError(t, err)
+	localizer := NewLocalizer(catalog)
+
+	notFound := errors.WithCode(errors.New("project not found", errors.Fields{"project_id": "p1"}), "PROJECT_NOT_FOUND")
+	for _, tc := range []struct {
+		lang string
+		want string
+	}{
+		{"fi", "Projektia p1 ei löytynyt."},
+		{"fi-FI", "Projektia p1 ei löytynyt."},
+		{"de", "Projekt p1 wurde nicht gefunden."},
+		{"sv-SE, de;q=0.8, en;q=0.5", "Projekt p1 wurde nicht gefunden."},
+		{"en-GB", "Project p1 was not found."},
+		{"sv", "Project p1 was not found."},
+		{"", "Project p1 was not found."},
+		{"%invalid%", "Project p1 was not found."},
+	} {
+		assert.Equal(t, tc.want, localizer.Localize(notFound, tc.lang), tc.lang)
+	}
+
+	// Falls back to English when the template is missing in the language.
+	quota := errors.WithCode(errors.New("quota exceeded", errors.Fields{"limit": 10}), "QUOTA_EXCEEDED")
+	assert.Equal(t, "Quota of 10 resources exceeded.", localizer.Localize(quota, "fi"))
+
+	// Falls back to the public message when there is no template or it fails.
+	publicErr := errors.WithPublicMessage(errors.WithCode(errors.New("error"), "UNKNOWN"), "Public message.")
+	assert.Equal(t, "Public message.", localizer.Localize(publicErr, "fi"))
+	assert.Equal(t, "Public message.", localizer.Localize(errors.WithCode(publicErr, "BROKEN"), "de"))
+	missingParam := errors.WithCode(errors.New("project not found"), "PROJECT_NOT_FOUND")
+	assert.Equal(t, errors.DefaultPublicMessage(errors.KindInternal), localizer.Localize(missingParam, "fi"))
+	assert.Equal(t, errors.DefaultPublicMessage(errors.KindInternal), localizer.Localize(stderrors.New("std"), "fi"))
+}
+
+func TestLocalize_RedactsParams(t *testing.T) {
+	localizer := NewLocalizer(MapCatalog{language.English: {"AUTH": "Invalid token {{.token}}."}})
+
+	err := errors.WithCode(errors.New("auth failed", errors.Fields{"token": "t0ken"}), "AUTH")
+	assert.Equal(t, "Invalid token [REDACTED].", localizer.Localize(err, "en"))
+}
+
+func TestSetCatalog(t *testing.T) {
+	defer SetCatalog(MapCatalog{})
+	SetCatalog(MapCatalog{language.Finnish: {"CODE": "Virhe."}})
+
+	assert.Equal(t, "Virhe.", Localize(errors.WithCode(errors.New("error"), "CODE"), "fi"))
+}
+
+// funcCatalog is Catalog other than MapCatalog.
+type funcCatalog func(tag language.Tag, code string) (string, bool)
+
+func (c funcCatalog) Languages() []language.Tag {
+	return []language.Tag{language.English}
+}
+
+func (c funcCatalog) Template(tag language.Tag, code string) (string, bool) {
+	return c(tag, code)
+}
+
+func TestLocalizer_CachesTemplates(t *testing.T) {
+	localizer := NewLocalizer(MapCatalog{language.English: {"CODE": "Error {{.id}}.", "BROKEN": "{{"}})
+	parsed, ok := localizer.templates.Load("Error {{.id}}.")
+	assert.True(t, ok)
+	assert.NotNil(t, parsed)
+	parsed, ok = localizer.templates.Load("{{")
+	assert.True(t, ok)
+	assert.Nil(t, parsed)
+
+	localizer = NewLocalizer(funcCatalog(func(language.Tag, string) (string, bool) {
+		return "Error {{.id}}.", true
+	}))
+	err := errors.WithCode(errors.New("error", errors.Fields{"id": 1}), "CODE")
+	assert.Equal(t, "Error 1.", localizer.Localize(err, "en"))
+	assert.Equal(t, "Error 1.", localizer.Localize(err, "en"))
+	_, ok = localizer.templates.Load("Error {{.id}}.")
+	assert.True(t, ok)
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package lambdaerrors provides AWS Lambda handler wrappers which recover panics, log the errors and convert them into
+// the responses expected by the triggers.
+package lambdaerrors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda/messages"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nordcloud/ncerrors/errors"
+)
+
+// KeyFunctionName is the field holding the Lambda function name.
+const KeyFunctionName = "function_name"
+
+// Handler is the Lambda handler signature accepted by lambda.Start.
+type Handler[TIn, TOut any] func(ctx context.Context, event TIn) (TOut, error)
+
+// Wrap returns the handler which:
+//   - attaches the Lambda request ID (as errors.KeyRequestID) and the function name (as KeyFunctionName) to the
+//     context with errors.ContextWithFields and to the returned errors,
+//   - recovers the panics into NCErrors, see errors.FromPanic,
+//   - logs the errors with errors.LogWithSeverity,
+//   - returns the errors as messages.InvokeResponse_Error with the error code (or kind if there is no code) as the
+//     error type, so e.g. the Step Functions can match it.
+//
+// Use WrapAPIGateway or WrapHTTPAPI for the API Gateway triggers.
+func Wrap[TIn, TOut any](handler Handler[TIn, TOut]) Handler[TIn, TOut] {
+	return func(ctx context.Context, event TIn) (TOut, error) {
+		var resp TOut
+		err := invoke(ctx, func(ctx context.Context) (err error) {
+			resp, err = handler(ctx, event)
+			return err
+		})
+		if err != nil {
+			var zero TOut
+			return zero, InvokeError(err)
+		}
+		return resp, nil
+	}
+}
+
+// WrapAPIGateway returns the API Gateway REST API (or HTTP API with the payload format 1.0) handler which behaves like
+// the one returned by Wrap except that the errors are converted into the problem details responses (see
+// ProblemResponse), so the handler never fails.
+func WrapAPIGateway(
+	handler Handler[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse],
+) Handler[events.APIGatewayProxyRequest, events.APIGatewayProxyResponse] {
+	return func(ctx context.Context, req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse, error) {
+		var resp events.APIGatewayProxyResponse
+		err := invoke(ctx, func(ctx context.Context) (err error) {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		if err != nil {
+			return ProblemResponse(err, req.Path), nil
+		}
+		return resp, nil
+	}
+}
+
+// WrapHTTPAPI returns the API Gateway HTTP API (payload format 2.0) handler which behaves like the one returned by
+// WrapAPIGateway.
+func WrapHTTPAPI(
+	handler Handler[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse],
+) Handler[events.APIGatewayV2HTTPRequest, events.APIGatewayV2HTTPResponse] {
+	return func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		var resp events.APIGatewayV2HTTPResponse
+		err := invoke(ctx, func(ctx context.Context) (err error) {
+			resp, err = handler(ctx, req)
+			return err
+		})
+		if err != nil {
+			problem := ProblemResponse(err, req.RawPath)
+			return events.APIGatewayV2HTTPResponse{
+				StatusCode: problem.StatusCode,
+				Headers:    problem.Headers,
+				Body:       problem.Body,
+			}, nil
+		}
+		return resp, nil
+	}
+}
+
+// ProblemResponse converts the error into the API Gateway response with the problem details body (see
+// errors.GetProblemDetails). The status is derived from the error code or kind, see errors.HTTPStatus.
+func ProblemResponse(err error, instance string) events.APIGatewayProxyResponse {
+	problem := errors.GetProblemDetails(err)
+	problem.Instance = instance
+	body, marshalErr := json.Marshal(problem)
+	if marshalErr != nil {
+		return events.APIGatewayProxyResponse{StatusCode: http.StatusInternalServerError}
+	}
+
+	return events.APIGatewayProxyResponse{
+		StatusCode: problem.Status,
+		Headers:    map[string]string{"Content-Type": errors.ProblemJSONContentType},
+		Body:       string(body),
+	}
+}
+
+// InvokeError converts the error into the structured Lambda error. The error type is the error code or the error
+// kind if there is no code. The message is redacted with the configured Redactor (see errors.SetRedactor).
+func InvokeError(err error) messages.InvokeResponse_Error {
+	errType := errors.GetCode(err)
+	if errType == "" {
+		errType = string(errors.GetKind(err))
+	}
+	return messages.InvokeResponse_Error{
+		Message: errors.RedactMessage(err.Error()),
+		Type:    errType,
+	}
+}
+
+// invoke calls fn with the Lambda fields attached to the context and returns the logged error.
+func invoke(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	fields := lambdaFields(ctx)
+	ctx = errors.ContextWithFields(ctx, fields)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.FromPanic(r)
+		}
+		if err != nil {
+			err = errors.WithDefaultFields(err, fields)
+			errors.LogWithSeverity(err)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+func lambdaFields(ctx context.Context) errors.Fields {
+	fields := errors.Fields{}
+	if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+		fields[errors.KeyRequestID] = lc.AwsRequestID
+	}
+	if lambdacontext.FunctionName != "" {
+		fields[KeyFunctionName] = lambdacontext.FunctionName
+	}
+	return fields
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package lambdaerrors
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda/messages"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLambdaContext() context.Context {
+	lambdacontext.FunctionName = "test-function"
+	return lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "req1"})
+}
+
+func TestWrap(t *testing.T) {
+	hook := test.NewGlobal()
+
+	handler := Wrap(func(ctx context.Context, event string) (string, error) {
+		if event == "fail" {
+			return "ignored", errors.WithCode(errors.NewCtx(ctx, "failed"), "TEST_FAILED")
+		}
+		return "ok:" + event, nil
+	})
+
+	resp, err := handler(newLambdaContext(), "event")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok:event", resp)
+	assert.Empty(t, hook.AllEntries())
+
+	resp, err = handler(newLambdaContext(), "fail")
+	assert.Equal(t, "", resp)
+	assert.Equal(t, messages.InvokeResponse_Error{Message: "failed", Type: "TEST_FAILED"}, err)
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	causes := entry.Data["error_context"].(errors.Fields)["causes"].([]errors.Cause)
+	assert.Equal(t, errors.Fields{errors.KeyRequestID: "req1", KeyFunctionName: "test-function"}, causes[0].Fields)
+}
+
+func TestWrap_Panic(t *testing.T) {
+	hook := test.NewGlobal()
+
+	handler := Wrap(func(ctx context.Context, event int) (int, error) {
+		panic("boom")
+	})
+
+	_, err := handler(newLambdaContext(), 1)
+	assert.Equal(t, messages.InvokeResponse_Error{Message: "panic: boom", Type: "internal"}, err)
+	assert.Equal(t, "panic: boom", hook.LastEntry().Message)
+	causes := hook.LastEntry().Data["error_context"].(errors.Fields)["causes"].([]errors.Cause)
+	assert.Equal(t, "TestWrap_Panic.func1", causes[0].FuncName)
+}
+
+func TestWrapAPIGateway(t *testing.T) {
+	test.NewGlobal()
+
+	handler := WrapAPIGateway(func(
+		ctx context.Context, req events.APIGatewayProxyRequest,
+	) (events.APIGatewayProxyResponse, error) {
+		switch req.Path {
+		case "/missing":
+			return events.APIGatewayProxyResponse{}, errors.WithPublicMessage(
+				errors.WithKind(errors.New("db row not found"), errors.KindNotFound), "The project does not exist.")
+		case "/panic":
+			panic(stderrors.New("boom"))
+		default:
+			return events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "ok"}, nil
+		}
+	})
+
+	resp, err := handler(newLambdaContext(), events.APIGatewayProxyRequest{Path: "/ok"})
+	assert.NoError(t, err)
+	assert.Equal(t, events.APIGatewayProxyResponse{StatusCode: http.StatusOK, Body: "ok"}, resp)
+
+	resp, err = handler(newLambdaContext(), events.APIGatewayProxyRequest{Path: "/missing"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, errors.ProblemJSONContentType, resp.Headers["Content-Type"])
+	assert.JSONEq(t, `{"title": "The project does not exist.", "status": 404, "instance": "/missing"}`, resp.Body)
+
+	resp, err = handler(newLambdaContext(), events.APIGatewayProxyRequest{Path: "/panic"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	var problem errors.ProblemDetails
+	assert.NoError(t, json.Unmarshal([]byte(resp.Body), &problem))
+	assert.Equal(t, errors.DefaultPublicMessage(errors.KindInternal), problem.Title)
+}
+
+func TestWrapHTTPAPI(t *testing.T) {
+	test.NewGlobal()
+
+	handler := WrapHTTPAPI(func(
+		ctx context.Context, req events.APIGatewayV2HTTPRequest,
+	) (events.APIGatewayV2HTTPResponse, error) {
+		return events.APIGatewayV2HTTPResponse{}, errors.WithKind(errors.New("invalid"), errors.KindInvalidArgument)
+	})
+
+	resp, err := handler(newLambdaContext(), events.APIGatewayV2HTTPRequest{RawPath: "/projects"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, errors.ProblemJSONContentType, resp.Headers["Content-Type"])
+	assert.JSONEq(t, `{"title": "The request is invalid.", "status": 400, "instance": "/projects"}`, resp.Body)
+}
+
+func TestWrap_KeepsErrorFields(t *testing.T) {
+	hook := test.NewGlobal()
+
+	handler := Wrap(func(ctx context.Context, event string) (string, error) {
+		return "", errors.New("user john@example.com failed", errors.RequestID("api-gw-req"))
+	})
+
+	_, err := handler(newLambdaContext(), "event")
+	assert.Equal(t, messages.InvokeResponse_Error{Message: "user [REDACTED] failed", Type: "internal"}, err)
+
+	causes := hook.LastEntry().Data["error_context"].(errors.Fields)["causes"].([]errors.Cause)
+	assert.Equal(t, errors.Fields{errors.KeyRequestID: "api-gw-req", KeyFunctionName: "test-function"}, causes[0].Fields)
+}
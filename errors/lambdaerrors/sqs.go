@@ -0,0 +1,144 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package lambdaerrors
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nordcloud/ncerrors/errors"
+)
+
+// Fields of the SQS message errors.
+const (
+	KeySQSMessageID = "sqs_message_id"
+	// SQSAttributePrefix prefixes the string message attributes added as fields.
+	SQSAttributePrefix = "sqs_attribute."
+)
+
+// SQSMessageHandler processes a single SQS message.
+type SQSMessageHandler func(ctx context.Context, msg events.SQSMessage) error
+
+// SQSOption configures ProcessSQSBatch.
+type SQSOption func(cfg *sqsConfig)
+
+type sqsConfig struct {
+	retryable func(err error) bool
+}
+
+// WithRetryable sets the function deciding whether the failed message should be retried. By default, the failures are
+// retried unless the error is classified as permanent, see IsPermanent.
+func WithRetryable(retryable func(err error) bool) SQSOption {
+	return func(cfg *sqsConfig) {
+		cfg.retryable = retryable
+	}
+}
+
+// SQSBatchResult is the result of ProcessSQSBatch.
+type SQSBatchResult struct {
+	// Errors holds the errors of the failed messages by the message ID.
+	Errors map[string]error
+	// Response reports the failed messages to be retried, the other messages are deleted from the queue.
+	Response events.SQSEventResponse
+}
+
+// ProcessSQSBatch calls the handler for every message of the batch. The message ID and the string message attributes
+// are attached to the handler context with errors.ContextWithFields and added to the errors of the failed messages.
+// The panics are recovered into NCErrors. Every failure is logged with errors.LogWithSeverity and, if retryable (see
+// WithRetryable), reported in the response. The non-retryable failures are dropped. For the FIFO queues, the messages following the
+// first retryable failure are not processed and reported as failures to keep the order.
+func ProcessSQSBatch(
+	ctx context.Context, event events.SQSEvent, handler SQSMessageHandler, opts ...SQSOption,
+) SQSBatchResult {
+	cfg := sqsConfig{retryable: func(err error) bool { return !IsPermanent(err) }}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	result := SQSBatchResult{
+		Errors:   map[string]error{},
+		Response: events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}},
+	}
+	blocked := false
+	for _, msg := range event.Records {
+		if blocked {
+			result.Response.BatchItemFailures = append(result.Response.BatchItemFailures,
+				events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+			continue
+		}
+
+		err := processSQSMessage(ctx, msg, handler)
+		if err == nil {
+			continue
+		}
+
+		result.Errors[msg.MessageId] = err
+		errors.LogWithSeverity(err)
+		if cfg.retryable(err) {
+			result.Response.BatchItemFailures = append(result.Response.BatchItemFailures,
+				events.SQSBatchItemFailure{ItemIdentifier: msg.MessageId})
+			blocked = strings.HasSuffix(msg.EventSourceARN, ".fifo")
+		}
+	}
+
+	return result
+}
+
+// WrapSQS returns the SQS handler which processes the batch with ProcessSQSBatch. The Lambda fields are attached to
+// the context like in Wrap. The function response types must be enabled in the event source mapping
+// (`ReportBatchItemFailures`).
+func WrapSQS(handler SQSMessageHandler, opts ...SQSOption) Handler[events.SQSEvent, events.SQSEventResponse] {
+	return func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+		var resp events.SQSEventResponse
+		err := invoke(ctx, func(ctx context.Context) error {
+			resp = ProcessSQSBatch(ctx, event, handler, opts...).Response
+			return nil
+		})
+		return resp, err
+	}
+}
+
+// IsPermanent tells if the message processing failed with the error won't succeed when retried. The errors with the
+// registered non-retryable code (see errors.CodeInfo) and the invalid argument, not found, conflict, permission denied
+// and unauthenticated errors are permanent. The unclassified errors and the recovered panics are not, so the messages
+// reach the redrive policy instead of being deleted from the queue.
+func IsPermanent(err error) bool {
+	if info, ok := errors.LookupCode(errors.GetCode(err)); ok {
+		return !info.Retryable
+	}
+
+	switch errors.GetKind(err) {
+	case errors.KindInvalidArgument, errors.KindNotFound, errors.KindConflict, errors.KindPermissionDenied,
+		errors.KindUnauthenticated:
+		return true
+	default:
+		return false
+	}
+}
+
+// processSQSMessage calls the handler and returns its error with the message fields.
+func processSQSMessage(ctx context.Context, msg events.SQSMessage, handler SQSMessageHandler) (err error) {
+	ctx = errors.ContextWithFields(ctx, sqsMessageFields(msg))
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.FromPanic(r)
+		}
+		if err != nil {
+			err = errors.WithFields(err, errors.FieldsFromContext(ctx))
+		}
+	}()
+
+	return handler(ctx, msg)
+}
+
+func sqsMessageFields(msg events.SQSMessage) errors.Fields {
+	fields := errors.Fields{KeySQSMessageID: msg.MessageId}
+	for name, attr := range msg.MessageAttributes {
+		if attr.StringValue != nil {
+			fields[SQSAttributePrefix+name] = *attr.StringValue
+		}
+	}
+	return fields
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package lambdaerrors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSQSEvent(queueARN string, bodies ...string) events.SQSEvent {
+	var event events.SQSEvent
+	for _, body := range bodies {
+		tenant := "t1"
+		event.Records = append(event.Records, events.SQSMessage{
+			MessageId:      "id-" + body,
+			Body:           body,
+			EventSourceARN: queueARN,
+			MessageAttributes: map[string]events.SQSMessageAttribute{
+				"tenant": {StringValue: &tenant, DataType: "String"},
+				"binary": {BinaryValue: []byte("data"), DataType: "Binary"},
+			},
+		})
+	}
+	return event
+}
+
+func handleTestMessage(ctx context.Context, msg events.SQSMessage) error {
+	switch msg.Body {
+	case "retry":
+		return errors.WithKind(errors.NewCtx(ctx, "dependency unavailable"), errors.KindUnavailable)
+	case "drop":
+		return errors.WithKind(errors.New("invalid message"), errors.KindInvalidArgument)
+	case "plain":
+		return fmt.Errorf("db: %w", context.DeadlineExceeded)
+	case "unclassified":
+		return fmt.Errorf("driver failure")
+	case "panic":
+		panic("boom")
+	default:
+		return nil
+	}
+}
+
+func TestProcessSQSBatch(t *testing.T) {
+	hook := test.NewGlobal()
+
+	event := newSQSEvent("arn:aws:sqs:eu-west-1:123:queue", "ok", "retry", "drop", "panic", "ok2")
+	result := ProcessSQSBatch(context.Background(), event, handleTestMessage)
+
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: "id-retry"}, {ItemIdentifier: "id-panic"}},
+		result.Response.BatchItemFailures)
+	assert.Len(t, result.Errors, 3)
+	assert.Len(t, hook.AllEntries(), 3)
+
+	ncErr, _ := errors.AsNC(result.Errors["id-drop"])
+	assert.Equal(t, errors.Fields{KeySQSMessageID: "id-drop", "sqs_attribute.tenant": "t1"}, ncErr.Causes[0].Fields)
+	assert.Equal(t, "panic: boom", result.Errors["id-panic"].Error())
+	tenant, _ := errors.GetField[string](result.Errors["id-retry"], SQSAttributePrefix+"tenant")
+	assert.Equal(t, "t1", tenant)
+}
+
+func TestProcessSQSBatch_Unclassified(t *testing.T) {
+	test.NewGlobal()
+
+	event := newSQSEvent("arn:aws:sqs:eu-west-1:123:queue", "unclassified", "plain", "panic")
+	result := ProcessSQSBatch(context.Background(), event, handleTestMessage)
+
+	assert.Equal(t, []events.SQSBatchItemFailure{
+		{ItemIdentifier: "id-unclassified"},
+		{ItemIdentifier: "id-plain"},
+		{ItemIdentifier: "id-panic"},
+	}, result.Response.BatchItemFailures)
+	assert.Len(t, result.Errors, 3)
+}
+
+func TestIsPermanent(t *testing.T) {
+	errors.RegisterCode(errors.CodeInfo{Code: "TEST_SQS_PERMANENT", Kind: errors.KindUnavailable})
+	errors.RegisterCode(errors.CodeInfo{Code: "TEST_SQS_RETRYABLE", Kind: errors.KindNotFound, Retryable: true})
+
+	assert.False(t, IsPermanent(fmt.Errorf("driver failure")))
+	assert.False(t, IsPermanent(errors.New("failure")))
+	assert.False(t, IsPermanent(errors.WithKind(errors.New("failure"), errors.KindUnavailable)))
+	assert.True(t, IsPermanent(errors.WithKind(errors.New("failure"), errors.KindNotFound)))
+	assert.True(t, IsPermanent(errors.NewCode("TEST_SQS_PERMANENT")))
+	assert.False(t, IsPermanent(errors.NewCode("TEST_SQS_RETRYABLE")))
+}
+
+func TestProcessSQSBatch_FIFO(t *testing.T) {
+	test.NewGlobal()
+
+	event := newSQSEvent("arn:aws:sqs:eu-west-1:123:queue.fifo", "ok", "drop", "retry", "ok2", "ok3")
+	result := ProcessSQSBatch(context.Background(), event, handleTestMessage)
+
+	assert.Equal(t, []events.SQSBatchItemFailure{
+		{ItemIdentifier: "id-retry"},
+		{ItemIdentifier: "id-ok2"},
+		{ItemIdentifier: "id-ok3"},
+	}, result.Response.BatchItemFailures)
+	assert.Len(t, result.Errors, 2)
+}
+
+func TestWrapSQS(t *testing.T) {
+	test.NewGlobal()
+
+	handler := WrapSQS(handleTestMessage, WithRetryable(func(err error) bool { return true }))
+	resp, err := handler(newLambdaContext(), newSQSEvent("arn:aws:sqs:eu-west-1:123:queue", "ok", "drop"))
+	assert.NoError(t, err)
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: "id-drop"}}, resp.BatchItemFailures)
+
+	resp, err = handler(newLambdaContext(), events.SQSEvent{})
+	assert.NoError(t, err)
+	assert.Empty(t, resp.BatchItemFailures)
+}
+
+func TestWrapSQS_LambdaFields(t *testing.T) {
+	test.NewGlobal()
+
+	var result SQSBatchResult
+	handler := func(ctx context.Context, event events.SQSEvent) (events.SQSEventResponse, error) {
+		result = ProcessSQSBatch(ctx, event, handleTestMessage)
+		return result.Response, nil
+	}
+	_, _ = Wrap(handler)(newLambdaContext(), newSQSEvent("arn:aws:sqs:eu-west-1:123:queue", "drop"))
+
+	functionName, _ := errors.GetField[string](result.Errors["id-drop"], KeyFunctionName)
+	assert.Equal(t, "test-function", functionName)
+}
@@ -0,0 +1,50 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import "sync"
+
+// LogObserver is called for every error logged by the logging functions of this package, e.g. to count the errors.
+type LogObserver func(err error, severity LogSeverity)
+
+type logObserverEntry struct {
+	observer LogObserver
+}
+
+var (
+	logObserversMu sync.RWMutex
+	logObservers   []*logObserverEntry
+)
+
+// AddLogObserver registers the observer and returns the function removing it. The observers are called also for
+// the errors suppressed by RateLimiter.
+func AddLogObserver(observer LogObserver) (remove func()) {
+	entry := &logObserverEntry{observer: observer}
+
+	logObserversMu.Lock()
+	defer logObserversMu.Unlock()
+	logObservers = append(logObservers, entry)
+
+	return func() {
+		logObserversMu.Lock()
+		defer logObserversMu.Unlock()
+		for i, e := range logObservers {
+			if e == entry {
+				logObservers = append(logObservers[:i:i], logObservers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// NotifyLogObservers calls the registered observers. It is used by the adapters for other logging libraries, so the
+// errors logged by them are observed as well.
+func NotifyLogObservers(err error, severity LogSeverity) {
+	logObserversMu.RLock()
+	observers := logObservers
+	logObserversMu.RUnlock()
+
+	for _, entry := range observers {
+		entry.observer(err, severity)
+	}
+}
@@ -3,17 +3,21 @@
 package errors
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	errorKey           = "error"
-	errorCtxKey        = "error_context"
-	errorStackKey      = "error_stack"
-	awsErrorCodeKey    = "aws_error_code"
-	awsErrorMessageKey = "aws_error_message"
+	errorKey            = "error"
+	errorCtxKey         = "error_context"
+	errorStackKey       = "error_stack"
+	awsErrorCodeKey     = "aws_error_code"
+	awsErrorMessageKey  = "aws_error_message"
+	errorDocsURLKey     = "error_docs_url"
+	errorOwnerKey       = "error_owner"
+	errorFingerprintKey = "error_fingerprint"
 )
 
 type contextBuilder func(nce *NCError) Fields
@@ -22,68 +26,88 @@ func getLogger(err error, builder contextBuilder) *logrus.Entry {
 	return logrus.WithFields(buildLogFields(err, builder))
 }
 
-// LogWithSeverity uses severity stored in the error to select appropriate log level.
+func contextLogger(builder contextBuilder) func(err error) *logrus.Entry {
+	return func(err error) *logrus.Entry {
+		return getLogger(err, builder)
+	}
+}
+
+func plainLogger(err error) *logrus.Entry {
+	return logrus.WithFields(buildPlainLogFields(err))
+}
+
+// logWith logs err at the severity unless suppressed by the configured RateLimiter (see SetRateLimiter). The log
+// observers are notified in any case. It returns false if the error was suppressed.
+func logWith(err error, severity LogSeverity, newEntry func(err error) *logrus.Entry) bool {
+	NotifyLogObservers(err, severity)
+	if !AllowLog(err, severity, logrusSummary(newEntry)) {
+		return false
+	}
+	newEntry(err).Log(logrusLevel(severity), RedactMessage(err.Error()))
+	return true
+}
+
+// LogWithSeverity uses severity stored in the error to select appropriate log level. Errors of ERROR severity are
+// also reported with the configured Reporter (see SetReporter) unless suppressed by the configured RateLimiter.
 func LogWithSeverity(err error) {
-	switch GetErrorSeverity(err) {
-	case ERROR:
-		LogError(err)
-	case WARN:
-		LogWarning(err)
-	case INFO:
-		LogInfo(err)
-	case DEBUG:
-		LogDebug(err)
+	severity := GetErrorSeverity(err)
+	switch severity {
+	case WARN, INFO, DEBUG:
 	default:
-		LogError(err)
+		severity = ERROR
+	}
+
+	if logWith(err, severity, contextLogger((*NCError).GetContext)) && severity == ERROR {
+		Report(err)
 	}
 }
 
 // LogError logs err with `logrus.Error` method (level=error).
 // (uses a newly created `logrus.Entry`)
 func LogError(err error) {
-	getLogger(err, (*NCError).GetContext).Error(err.Error())
+	logWith(err, ERROR, contextLogger((*NCError).GetContext))
 }
 
 // LogWarning logs err at level = warning.
 // (uses a newly created `logrus.Entry`)
 func LogWarning(err error) {
-	getLogger(err, (*NCError).GetContext).Warn(err.Error())
+	logWith(err, WARN, contextLogger((*NCError).GetContext))
 }
 
 // LogInfo logs err at level = info.
 // (uses a newly created `logrus.Entry`)
 func LogInfo(err error) {
-	getLogger(err, (*NCError).GetContext).Info(err.Error())
+	logWith(err, INFO, contextLogger((*NCError).GetContext))
 }
 
 // LogDebug logs err at level = debug.
 // (uses a newly created `logrus.Entry`)
 func LogDebug(err error) {
-	getLogger(err, (*NCError).GetMergedFieldsContext).Debug(err.Error())
+	logWith(err, DEBUG, contextLogger((*NCError).GetMergedFieldsContext))
 }
 
 // LogErrorMerged logs err with `logrus.Error` method (level=error) and merged fields as context.
 // (uses a newly created `logrus.Entry`)
 func LogErrorMerged(err error) {
-	getLogger(err, (*NCError).GetMergedFieldsContext).Error(err.Error())
+	logWith(err, ERROR, contextLogger((*NCError).GetMergedFieldsContext))
 }
 
 // LogWarningMerged logs err at level = warning. and merged fields as context.
 // (uses a newly created `logrus.Entry`)
 func LogWarningMerged(err error) {
-	getLogger(err, (*NCError).GetMergedFieldsContext).Warn(err.Error())
+	logWith(err, WARN, contextLogger((*NCError).GetMergedFieldsContext))
 }
 
 // LogInfoMerged logs err at level = info. and merged fields as context.
 // (uses a newly created `logrus.Entry`)
 func LogInfoMerged(err error) {
-	getLogger(err, (*NCError).GetMergedFieldsContext).Info(err.Error())
+	logWith(err, INFO, contextLogger((*NCError).GetMergedFieldsContext))
 }
 
 // LogDebugMerged logs err at level = debug. and merged fields as context.
 // (uses a newly created `logrus.Entry`)
 func LogDebugMerged(err error) {
-	getLogger(err, (*NCError).GetMergedFieldsContext).Debug(err.Error())
+	logWith(err, DEBUG, contextLogger((*NCError).GetMergedFieldsContext))
 }
 
 // GetLogFields converts an error into `logrus.Fields`. It will set an `error` field so you don't have to use the
@@ -102,87 +126,145 @@ func GetMergedLogFields(err error) logrus.Fields {
 
 // LogErrorPlain logs error with its merged fields and stack at level = Error.
 func LogErrorPlain(err error) {
-	logrus.WithFields(buildPlainLogFields(err)).Error(err.Error())
+	logWith(err, ERROR, plainLogger)
 }
 
 // LogWarningPlain logs error with its merged fields and stack at level = Warning.
 func LogWarningPlain(err error) {
-	logrus.WithFields(buildPlainLogFields(err)).Warning(err.Error())
+	logWith(err, WARN, plainLogger)
 }
 
 // LogInfoPlain logs error with its merged fields and stack at level = Info.
 func LogInfoPlain(err error) {
-	logrus.WithFields(buildPlainLogFields(err)).Info(err.Error())
+	logWith(err, INFO, plainLogger)
 }
 
 // LogDebugPlain logs error with its merged fields and stack at level = Debug.
 func LogDebugPlain(err error) {
-	logrus.WithFields(buildPlainLogFields(err)).Debug(err.Error())
+	logWith(err, DEBUG, plainLogger)
 }
 
-func buildLogFields(err error, buildContext contextBuilder) logrus.Fields {
-	nativeError := errors.Cause(err)
-	if ncError, ok := nativeError.(NCError); ok {
-		//rootError is AWS error
-		rootError := errors.Cause(ncError.RootError)
-		if awsErr, ok := rootError.(awserr.Error); ok {
-			return logrus.Fields{
-				errorKey:           ncError.Error(),
-				errorCtxKey:        buildContext(&ncError),
-				awsErrorCodeKey:    awsErr.Code(),
-				awsErrorMessageKey: awsErr.Message(),
-			}
-		}
+// LogDetails is a logger-agnostic representation of the error. It is used to build log fields both by this package and
+// by the adapters for other logging libraries.
+type LogDetails struct {
+	// Message is the error message redacted with the configured Redactor. For AWS errors the original error message
+	// is used if present.
+	Message string
+	// NCError is the NCError found in the error chain, nil if there is none. Its fields are redacted with the
+	// configured Redactor (see SetRedactor) and bounded by the configured FieldLimits (see SetFieldLimits).
+	NCError *NCError
+	// Severity is the outermost NCError severity or ERROR.
+	Severity LogSeverity
+	// AWSError is the AWS error found in the error chain, nil if there is none. For NCError only its root error is
+	// searched.
+	AWSError awserr.Error
+	// DocsURL is the documentation URL of the error, see GetDocsURL.
+	DocsURL string
+	// Owner is the team owning the error, see GetOwner.
+	Owner string
+	// Fingerprint groups the same failures together, see Fingerprint and SetFingerprintOptions. It is set for NCError
+	// only.
+	Fingerprint string
+}
+
+// GetLogDetails extracts LogDetails from the error. It returns zero LogDetails for nil error.
+func GetLogDetails(err error) LogDetails {
+	if err == nil {
+		return LogDetails{}
+	}
 
-		return logrus.Fields{
-			errorKey:    ncError.Error(),
-			errorCtxKey: buildContext(&ncError),
+	if ncError, ok := AsNC(err); ok {
+		ncError = limitNCError(redactNCError(ncError))
+		details := LogDetails{
+			Message:     ncError.Error(),
+			NCError:     &ncError,
+			Severity:    GetErrorSeverity(ncError),
+			DocsURL:     GetDocsURL(ncError),
+			Owner:       GetOwner(ncError),
+			Fingerprint: Fingerprint(ncError, getFingerprintOptions()...),
 		}
+		//rootError is AWS error
+		if awsErr, ok := AsType[awserr.Error](ncError.RootError); ok {
+			details.AWSError = awsErr
+		}
+		return details
 	}
+
 	//error is not NCError but still it is AWS error
-	if awsErr, ok := nativeError.(awserr.Error); ok {
-		errKey := awsErr.Error()
-		if awsErr.OrigErr() != nil {
-			errKey = awsErr.OrigErr().Error()
+	if awsErr, ok := AsType[awserr.Error](err); ok {
+		details := LogDetails{
+			Message:  RedactMessage(awsErr.Error()),
+			Severity: ERROR,
+			AWSError: awsErr,
 		}
-		return logrus.Fields{
-			errorKey:           errKey,
-			awsErrorCodeKey:    awsErr.Code(),
-			awsErrorMessageKey: awsErr.Message(),
+		if awsErr.OrigErr() != nil {
+			details.Message = RedactMessage(awsErr.OrigErr().Error())
 		}
+		return details
 	}
-	if err != nil {
-		return logrus.Fields{errorKey: err.Error()}
+
+	// fmt.Sprint renders the errors panicking on nil receiver (e.g. nil *NCError) as `<nil>`.
+	return LogDetails{Message: RedactMessage(fmt.Sprint(err)), Severity: ERROR}
+}
+
+// MergedLogFields returns the log fields of the details, the same as GetMergedLogFields returns for the error the
+// details were extracted from. Use it to avoid extracting the details twice.
+func (d LogDetails) MergedLogFields() logrus.Fields {
+	return detailsLogFields(d, (*NCError).GetMergedFieldsContext)
+}
+
+func buildLogFields(err error, buildContext contextBuilder) logrus.Fields {
+	if err == nil {
+		return logrus.Fields{errorKey: nil}
 	}
-	return logrus.Fields{errorKey: nil}
+
+	return detailsLogFields(GetLogDetails(err), buildContext)
+}
+
+func detailsLogFields(details LogDetails, buildContext contextBuilder) logrus.Fields {
+	logFields := logrus.Fields{errorKey: details.Message}
+	if details.NCError != nil {
+		logFields[errorCtxKey] = buildContext(details.NCError)
+	}
+	addAWSLogFields(logFields, details)
+	addMetadataLogFields(logFields, details)
+
+	return logFields
 }
 
 func buildPlainLogFields(err error) logrus.Fields {
-	nativeError := errors.Cause(err)
-	if ncError, ok := nativeError.(NCError); ok {
-		logFields := logrus.Fields(ncError.GetMergedFields())
-		logFields[errorKey] = ncError.Error()
-		logFields[errorStackKey] = ncError.Stack
+	if err == nil {
+		return logrus.Fields{errorKey: nil}
+	}
 
-		//rootError is AWS error
-		rootError := errors.Cause(ncError.RootError)
-		if awsErr, ok := rootError.(awserr.Error); ok {
-			logFields[awsErrorCodeKey] = awsErr.Code()
-			logFields[awsErrorMessageKey] = awsErr.Message()
-		}
+	details := GetLogDetails(err)
+	logFields := logrus.Fields{}
+	if details.NCError != nil {
+		logFields = logrus.Fields(details.NCError.GetMergedFields())
+		logFields[errorStackKey] = details.NCError.Stack
+	}
+	logFields[errorKey] = details.Message
+	addAWSLogFields(logFields, details)
+	addMetadataLogFields(logFields, details)
+
+	return logFields
+}
 
-		return logFields
+func addAWSLogFields(logFields logrus.Fields, details LogDetails) {
+	if details.AWSError != nil {
+		logFields[awsErrorCodeKey] = details.AWSError.Code()
+		logFields[awsErrorMessageKey] = RedactMessage(details.AWSError.Message())
 	}
-	//error is not NCError but still it is AWS error
-	if awsErr, ok := nativeError.(awserr.Error); ok {
-		return logrus.Fields{
-			errorKey:           awsErr.OrigErr().Error(),
-			awsErrorCodeKey:    awsErr.Code(),
-			awsErrorMessageKey: awsErr.Message(),
-		}
+}
+
+func addMetadataLogFields(logFields logrus.Fields, details LogDetails) {
+	if details.DocsURL != "" {
+		logFields[errorDocsURLKey] = details.DocsURL
+	}
+	if details.Owner != "" {
+		logFields[errorOwnerKey] = details.Owner
 	}
-	if err != nil {
-		return logrus.Fields{errorKey: err.Error()}
+	if details.Fingerprint != "" {
+		logFields[errorFingerprintKey] = details.Fingerprint
 	}
-	return logrus.Fields{errorKey: nil}
 }
@@ -4,6 +4,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -38,7 +39,7 @@ func TestGetLogger_NCError(t *testing.T) {
 		Fields:   Fields{"field1": "val1"},
 		FuncName: "TestGetLogger_NCError",
 		FileName: "github.com/nordcloud/ncerrors/errors/logging_test.go",
-		Line:     27,
+		Line:     28,
 		Severity: ERROR,
 	}, // this value must be updated according to the line number when the error has actually occured
 		Cause{Message: errorMessage}}, errCtx["causes"])
@@ -113,7 +114,7 @@ func TestGetLogField_NCError(t *testing.T) {
 		Fields:   Fields{"field1": "val1"},
 		FuncName: "TestGetLogField_NCError",
 		FileName: "github.com/nordcloud/ncerrors/errors/logging_test.go",
-		Line:     102,
+		Line:     103,
 		Severity: ERROR,
 	}, // this value must be updated according to the line number when the error has actually occured
 		Cause{Message: errorMessage}}, errCtx["causes"])
@@ -141,3 +142,76 @@ func TestGetMergedLogField_NCError(t *testing.T) {
 		"field2": "override",
 	}, errCtx["fields"])
 }
+
+func TestGetLogFields_NCErrorPointer(t *testing.T) {
+	awsErr := awserr.New("code", "message", errors.New("org error"))
+	ncErr := WithContext(awsErr, "context 1", Fields{"field1": "val1"}).(NCError)
+
+	for _, err := range []error{&ncErr, fmt.Errorf("std wrap: %w", &ncErr)} {
+		logFields := GetLogFields(err)
+		assert.Equal(t, ncErr.Error(), logFields[errorKey])
+		assert.Equal(t, "code", logFields[awsErrorCodeKey])
+		assert.Contains(t, logFields, errorCtxKey)
+
+		plainFields := buildPlainLogFields(err)
+		assert.Equal(t, "val1", plainFields["field1"])
+		assert.Equal(t, "code", plainFields[awsErrorCodeKey])
+	}
+}
+
+func TestGetLogFields_DocsURLAndOwner(t *testing.T) {
+	err := WithOwner(WithDocsURL(New("error"), "https://runbooks.example.com/error"), "team-a")
+
+	logFields := GetLogFields(Wrap(err, "level2"))
+	assert.Equal(t, "https://runbooks.example.com/error", logFields[errorDocsURLKey])
+	assert.Equal(t, "team-a", logFields[errorOwnerKey])
+
+	plainFields := buildPlainLogFields(err)
+	assert.Equal(t, "https://runbooks.example.com/error", plainFields[errorDocsURLKey])
+	assert.Equal(t, "team-a", plainFields[errorOwnerKey])
+
+	assert.NotContains(t, GetLogFields(New("error")), errorOwnerKey)
+}
+
+func TestGetLogFields_Fingerprint(t *testing.T) {
+	err := Wrap(New("error", Fields{"id": 1}), "level2")
+
+	assert.Equal(t, Fingerprint(err), GetLogFields(err)[errorFingerprintKey])
+	assert.Equal(t, Fingerprint(err), buildPlainLogFields(err)[errorFingerprintKey])
+
+	defer SetFingerprintOptions()
+	SetFingerprintOptions(WithFingerprintFrames(0))
+	assert.Equal(t, Fingerprint(err, WithFingerprintFrames(0)), GetLogFields(err)[errorFingerprintKey])
+}
+
+func TestAddLogObserver(t *testing.T) {
+	var observed []LogSeverity
+	remove := AddLogObserver(func(err error, severity LogSeverity) {
+		observed = append(observed, severity)
+	})
+
+	LogWithSeverity(NewWithSeverity("error", nil, WARN))
+	LogDebugPlain(New("error"))
+	remove()
+	LogError(New("error"))
+
+	assert.Equal(t, []LogSeverity{WARN, DEBUG}, observed)
+}
+
+func TestLogDetails_MergedLogFields(t *testing.T) {
+	err := WithContext(awserr.New("code", "message", nil), "level1", Fields{"field1": "val1"})
+
+	assert.Equal(t, GetMergedLogFields(err), GetLogDetails(err).MergedLogFields())
+}
+
+func TestGetLogFields_FingerprintRedacted(t *testing.T) {
+	var errs []error
+	for _, user := range []string{"alice@example.com", "bob@example.com"} {
+		errs = append(errs, New("user "+user+" not allowed"))
+	}
+
+	assert.Equal(t, Fingerprint(errs[0]), GetLogFields(errs[0])[errorFingerprintKey])
+	assert.Equal(t, Fingerprint(errs[0]), defaultRateLimitKey(errs[0]))
+	assert.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+	assert.Equal(t, Fingerprint(errors.New("std alice@example.com")), Fingerprint(errors.New("std bob@example.com")))
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusHook is a `logrus.Hook` which expands NCError found in the entry's `error` field (as set by
+// `logrus.WithError`) into the same fields as returned by GetMergedLogFields. It allows legacy
+// `logrus.WithError(err).Error(...)` call sites to log the error context without being rewritten.
+type LogrusHook struct {
+	// OverrideLevel sets the entry level from the error severity (see GetErrorSeverity). The level is overridden only
+	// if it is enabled in the entry's logger, so errors are never logged below the logger's level.
+	OverrideLevel bool
+}
+
+// NewLogrusHook returns LogrusHook. Use `logrus.AddHook(errors.NewLogrusHook(true))` to register it.
+func NewLogrusHook(overrideLevel bool) *LogrusHook {
+	return &LogrusHook{OverrideLevel: overrideLevel}
+}
+
+// Levels returns all logrus levels as the hook applies to every entry.
+func (h *LogrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire enriches the entry with the NCError context. The `error` field is replaced by the error with the message redacted
+// by the configured Redactor (see SetRedactor), which unwraps to the original error, so the formatters render the
+// redacted message and the hooks fired later still get the error. Entries without NCError are not enriched.
+func (h *LogrusHook) Fire(entry *logrus.Entry) error {
+	err, ok := entry.Data[logrus.ErrorKey].(error)
+	if !ok {
+		return nil
+	}
+	if _, ok := err.(redactedError); !ok {
+		entry.Data[logrus.ErrorKey] = redactedError{err: err}
+	}
+	if _, ok := AsNC(err); !ok {
+		return nil
+	}
+
+	for k, v := range GetMergedLogFields(err) {
+		if k == logrus.ErrorKey {
+			continue
+		}
+		entry.Data[k] = v
+	}
+
+	if h.OverrideLevel {
+		level := logrusLevel(GetErrorSeverity(err))
+		if entry.Logger == nil || entry.Logger.IsLevelEnabled(level) {
+			entry.Level = level
+		}
+	}
+
+	return nil
+}
+
+// redactedError is the error with the message redacted by the configured Redactor. It unwraps to the original error.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return RedactMessage(e.err.Error())
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+// logrusLevel converts LogSeverity into logrus.Level. Unknown severities are mapped to logrus.ErrorLevel.
+func logrusLevel(severity LogSeverity) logrus.Level {
+	switch severity {
+	case WARN:
+		return logrus.WarnLevel
+	case INFO:
+		return logrus.InfoLevel
+	case DEBUG:
+		return logrus.DebugLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
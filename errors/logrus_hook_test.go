@@ -0,0 +1,87 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// newHookedLogger returns logger with LogrusHook registered before the test hook, so the test hook records the
+// enriched entries.
+func newHookedLogger(overrideLevel bool) (*logrus.Logger, *test.Hook) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	logger.AddHook(NewLogrusHook(overrideLevel))
+	return logger, test.NewLocal(logger)
+}
+
+func TestLogrusHook_NCError(t *testing.T) {
+	logger, hook := newHookedLogger(false)
+
+	awsErr := awserr.New("code", "message", nil)
+	err := WithContextAndSeverity(awsErr, "level1", WARN, Fields{"field1": "val1"})
+	logger.WithError(err).Error("failed")
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, redactedError{err: err}, entry.Data[logrus.ErrorKey])
+	assert.Equal(t, "code", entry.Data[awsErrorCodeKey])
+	errCtx := entry.Data[errorCtxKey].(Fields)
+	assert.Equal(t, Fields{"field1": "val1"}, errCtx["fields"])
+	assert.Equal(t, err.(NCError).Stack, errCtx["stack"])
+}
+
+func TestLogrusHook_OverrideLevel(t *testing.T) {
+	logger, hook := newHookedLogger(true)
+	logger.SetLevel(logrus.InfoLevel)
+
+	logger.WithError(NewWithSeverity("warning", nil, WARN)).Error("failed")
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+
+	// Debug level is disabled so the original level is kept.
+	logger.WithError(NewWithSeverity("debug", nil, DEBUG)).Error("failed")
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+}
+
+func TestLogrusHook_StandardError(t *testing.T) {
+	logger, hook := newHookedLogger(true)
+
+	err := errors.New("std")
+	logger.WithError(err).Warn("failed")
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, logrus.Fields{logrus.ErrorKey: redactedError{err: err}}, entry.Data)
+}
+
+func TestLogrusHook_RedactsError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(NewLogrusHook(false))
+	hook := test.NewLocal(logger)
+
+	err := New("login failed for alice@example.com")
+	logger.WithError(err).Error("failed")
+
+	assert.NotContains(t, buf.String(), "alice@example.com")
+	assert.Contains(t, buf.String(), `"error":"login failed for [REDACTED]"`)
+	logged := hook.LastEntry().Data[logrus.ErrorKey].(error)
+	assert.True(t, Is(logged, err))
+	ncError, ok := AsNC(logged)
+	assert.True(t, ok)
+	assert.Equal(t, err.(NCError).Stack, ncError.Stack)
+
+	buf.Reset()
+	logger.WithError(errors.New("std alice@example.com")).Error("failed")
+	assert.Contains(t, buf.String(), `"error":"std [REDACTED]"`)
+}
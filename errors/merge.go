@@ -0,0 +1,177 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"reflect"
+	"sync/atomic"
+)
+
+// ConflictsKey holds the overridden field values when merging with WithConflicts option. The user's field of the same
+// name is merged like any other field; if there are conflicts it is kept among them under ConflictsKey.
+const ConflictsKey = "field_conflicts"
+
+var mergeOptions atomic.Pointer[[]MergeOption]
+
+// FieldConflict is a field value overridden when merging the causes' fields.
+type FieldConflict struct {
+	Value interface{} `json:"value"`
+	// FuncName is the function name of the cause which set the value.
+	FuncName string `json:"func"`
+}
+
+// causeConflicts are the values overridden within a cause's own Fields, stored under ConflictsKey by mergeFields. The
+// own type tells them apart from the user's value stored under ConflictsKey.
+type causeConflicts map[string][]FieldConflict
+
+// MergeOption configures merging of the causes' fields.
+type MergeOption func(cfg *mergeConfig)
+
+type mergeConfig struct {
+	keepConflicts bool
+	innermostWins map[string]bool
+}
+
+// WithConflicts preserves the overridden field values. They are stored under ConflictsKey as Fields with
+// []FieldConflict values, ordered from the outermost cause. Repeated equal values are not reported.
+func WithConflicts() MergeOption {
+	return func(cfg *mergeConfig) {
+		cfg.keepConflicts = true
+	}
+}
+
+// WithInnermostWins takes the values of the given keys from the innermost cause instead of the outermost one. It
+// applies to GetField too.
+func WithInnermostWins(keys ...string) MergeOption {
+	return func(cfg *mergeConfig) {
+		for _, key := range keys {
+			cfg.innermostWins[key] = true
+		}
+	}
+}
+
+// SetMergeOptions sets the options used by GetMergedFields and so by all the logging functions and serializers of
+// this package.
+func SetMergeOptions(opts ...MergeOption) {
+	mergeOptions.Store(&opts)
+}
+
+func getMergeOptions() []MergeOption {
+	if opts := mergeOptions.Load(); opts != nil {
+		return *opts
+	}
+	return nil
+}
+
+func newMergeConfig(opts []MergeOption) mergeConfig {
+	cfg := mergeConfig{innermostWins: map[string]bool{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// mergeFields merges slice of Fields. Rightmost Fields key-values overrides left Fields key-values when keys are
+// duplicated, i.e. the Fields are treated like the causes ordered from the innermost one. The options set with
+// SetMergeOptions apply: the leftmost value of the innermost-wins keys is kept and, with WithConflicts, the overridden
+// values are stored under ConflictsKey (the function name is filled in when the causes are merged). It is added to
+// handle Fields slice when more than 1 Fields is passed. This function allows to make Fields optional in New, Wrap and
+// other functions.
+func mergeFields(fields ...Fields) Fields {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if len(fields) == 1 {
+		return fields[0]
+	}
+
+	cfg := newMergeConfig(getMergeOptions())
+	merged := make(Fields)
+	conflicts := causeConflicts{}
+	for _, m := range fields {
+		for _, k := range sortedKeys(m) {
+			v := m[k]
+			current, exists := merged[k]
+			if !exists {
+				merged[k] = v
+				continue
+			}
+
+			overridden := v
+			if !cfg.innermostWins[k] {
+				overridden = current
+				merged[k] = v
+			}
+			if cfg.keepConflicts && !reflect.DeepEqual(current, v) {
+				conflicts[k] = append([]FieldConflict{{Value: overridden}}, conflicts[k]...)
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		if userValue, ok := merged[ConflictsKey]; ok {
+			// The user's value is kept as the overridden one.
+			conflicts[ConflictsKey] = append(conflicts[ConflictsKey], FieldConflict{Value: userValue})
+		}
+		merged[ConflictsKey] = conflicts
+	}
+	return merged
+}
+
+// MergeFields returns fields merged from every error's cause's fields. By default, if the field with the same name is
+// present in multiple causes the value from the outermost cause is taken and the other values are dropped.
+func (n *NCError) MergeFields(opts ...MergeOption) Fields {
+	cfg := newMergeConfig(opts)
+
+	errFields := Fields{}
+	setBy := map[string]string{}
+	conflicts := Fields{}
+	for _, cause := range n.Causes {
+		for _, k := range sortedKeys(cause.Fields) {
+			v := cause.Fields[k]
+			if ownConflicts, ok := v.(causeConflicts); ok && k == ConflictsKey {
+				// The values overridden within the cause's own Fields, see mergeFields.
+				if cfg.keepConflicts {
+					for _, ck := range sortedKeys(ownConflicts) {
+						kConflicts, _ := conflicts[ck].([]FieldConflict)
+						for _, conflict := range ownConflicts[ck] {
+							conflict.FuncName = cause.FuncName
+							kConflicts = append(kConflicts, conflict)
+						}
+						conflicts[ck] = kConflicts
+					}
+				}
+				continue
+			}
+
+			current, exists := errFields[k]
+			if !exists {
+				errFields[k] = v
+				setBy[k] = cause.FuncName
+				continue
+			}
+
+			overridden := FieldConflict{Value: v, FuncName: cause.FuncName}
+			if cfg.innermostWins[k] {
+				overridden = FieldConflict{Value: current, FuncName: setBy[k]}
+				errFields[k] = v
+				setBy[k] = cause.FuncName
+			}
+			if cfg.keepConflicts && !reflect.DeepEqual(current, v) {
+				kConflicts, _ := conflicts[k].([]FieldConflict)
+				conflicts[k] = append(kConflicts, overridden)
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		if userValue, ok := errFields[ConflictsKey]; ok {
+			// The user's value is kept as the overridden one.
+			kConflicts, _ := conflicts[ConflictsKey].([]FieldConflict)
+			conflicts[ConflictsKey] = append(kConflicts, FieldConflict{Value: userValue, FuncName: setBy[ConflictsKey]})
+		}
+		errFields[ConflictsKey] = conflicts
+	}
+	return errFields
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package otelerrors records NCError on OpenTelemetry spans and adds the trace IDs to the error fields. Register
+// TraceFields with `errors.AddContextFields(otelerrors.TraceFields)`, so the errors created with errors.NewCtx and
+// errors.WrapCtx carry the IDs of the active span.
+package otelerrors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// SeverityKey is the attribute key of the error severity.
+	SeverityKey = attribute.Key("error.severity")
+	// CodeKey is the attribute key of the error code, see errors.GetCode.
+	CodeKey = attribute.Key("error.code")
+	// AWSErrorCodeKey is the attribute key of the AWS error code.
+	AWSErrorCodeKey = attribute.Key("error.aws_error_code")
+	// FieldKeyPrefix prefixes the attribute keys of the error fields.
+	FieldKeyPrefix = "error.fields."
+)
+
+// Fields added by TraceFields.
+const (
+	KeyTraceID = "trace_id"
+	KeySpanID  = "span_id"
+)
+
+// TraceFields returns the trace and span IDs of the valid span context carried by ctx as KeyTraceID and KeySpanID
+// fields. It is errors.ContextFieldsFunc to be registered with errors.AddContextFields.
+func TraceFields(ctx context.Context) errors.Fields {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return nil
+	}
+
+	return errors.Fields{
+		KeyTraceID: spanCtx.TraceID().String(),
+		KeySpanID:  spanCtx.SpanID().String(),
+	}
+}
+
+// RecordError records the error on the span as an exception event. For NCError the event contains the stack trace
+// from NCError.Stack and the merged fields as attributes. The span status is set to codes.Error only for errors with
+// ERROR severity, so warnings and lower severities do not mark the span as failed.
+func RecordError(span trace.Span, err error, options ...trace.EventOption) {
+	if err == nil || !span.IsRecording() {
+		return
+	}
+
+	span.AddEvent(semconv.ExceptionEventName, append(options, trace.WithAttributes(Attributes(err)...))...)
+	if errors.GetErrorSeverity(err) == errors.ERROR {
+		span.SetStatus(codes.Error, errors.RedactMessage(err.Error()))
+	}
+}
+
+// Attributes returns the exception attributes of the error.
+func Attributes(err error) []attribute.KeyValue {
+	details := errors.GetLogDetails(err)
+	attrs := []attribute.KeyValue{
+		semconv.ExceptionType(fmt.Sprintf("%T", errors.GetRootError(err))),
+		semconv.ExceptionMessage(details.Message),
+		SeverityKey.String(string(details.Severity)),
+	}
+	if details.AWSError != nil {
+		attrs = append(attrs, AWSErrorCodeKey.String(details.AWSError.Code()))
+	}
+	if details.NCError == nil {
+		return attrs
+	}
+
+	if code := errors.GetCode(details.NCError); code != "" {
+		attrs = append(attrs, CodeKey.String(code))
+	}
+	attrs = append(attrs, semconv.ExceptionStacktrace(strings.Join(details.NCError.Stack, "\n")))
+	return append(attrs, FieldAttributes(details.NCError.GetMergedFields())...)
+}
+
+// FieldAttributes converts fields into attributes with keys prefixed with FieldKeyPrefix. The attributes are sorted by
+// key. Values of types not supported by attributes are formatted with fmt.Sprint.
+func FieldAttributes(fields errors.Fields) []attribute.KeyValue {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]attribute.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, fieldAttribute(attribute.Key(FieldKeyPrefix+k), fields[k]))
+	}
+	return attrs
+}
+
+func fieldAttribute(key attribute.Key, val interface{}) attribute.KeyValue {
+	switch v := val.(type) {
+	case string:
+		return key.String(v)
+	case bool:
+		return key.Bool(v)
+	case int:
+		return key.Int(v)
+	case int64:
+		return key.Int64(v)
+	case float64:
+		return key.Float64(v)
+	case []string:
+		return key.StringSlice(v)
+	case fmt.Stringer:
+		return key.String(v.String())
+	default:
+		return key.String(fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package otelerrors
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func recordInSpan(err error) tracetest.SpanStub {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "test")
+	RecordError(span, err)
+	span.End()
+
+	return exporter.GetSpans()[0]
+}
+
+func TestRecordError_NCError(t *testing.T) {
+	awsErr := awserr.New("code", "aws message", nil)
+	err := errors.WithContext(awsErr, "level1", errors.Fields{"field1": "val1", "field2": 2})
+
+	span := recordInSpan(err)
+
+	assert.Equal(t, codes.Error, span.Status.Code)
+	assert.Equal(t, err.Error(), span.Status.Description)
+	assert.Len(t, span.Events, 1)
+	assert.Equal(t, semconv.ExceptionEventName, span.Events[0].Name)
+
+	attrs := attribute.NewSet(span.Events[0].Attributes...)
+	message, _ := attrs.Value(semconv.ExceptionMessageKey)
+	assert.Equal(t, err.Error(), message.AsString())
+	exceptionType, _ := attrs.Value(semconv.ExceptionTypeKey)
+	assert.Equal(t, "*awserr.baseError", exceptionType.AsString())
+	stack, _ := attrs.Value(semconv.ExceptionStacktraceKey)
+	assert.Equal(t, strings.Join(err.(errors.NCError).Stack, "\n"), stack.AsString())
+	severity, _ := attrs.Value(SeverityKey)
+	assert.Equal(t, "error", severity.AsString())
+	awsCode, _ := attrs.Value(AWSErrorCodeKey)
+	assert.Equal(t, "code", awsCode.AsString())
+	field1, _ := attrs.Value(FieldKeyPrefix + "field1")
+	assert.Equal(t, "val1", field1.AsString())
+	field2, _ := attrs.Value(FieldKeyPrefix + "field2")
+	assert.Equal(t, int64(2), field2.AsInt64())
+}
+
+func TestRecordError_WarningDoesNotFailSpan(t *testing.T) {
+	span := recordInSpan(errors.NewWithSeverity("warning", nil, errors.WARN))
+
+	assert.Equal(t, codes.Unset, span.Status.Code)
+	assert.Len(t, span.Events, 1)
+}
+
+func TestRecordError_StandardError(t *testing.T) {
+	span := recordInSpan(stderrors.New("std"))
+
+	assert.Equal(t, codes.Error, span.Status.Code)
+	attrs := attribute.NewSet(span.Events[0].Attributes...)
+	_, hasStack := attrs.Value(semconv.ExceptionStacktraceKey)
+	assert.False(t, hasStack)
+}
+
+func TestRecordError_Nil(t *testing.T) {
+	span := recordInSpan(nil)
+
+	assert.Equal(t, codes.Unset, span.Status.Code)
+	assert.Empty(t, span.Events)
+}
+
+func TestTraceFields(t *testing.T) {
+	defer errors.AddContextFields(TraceFields)()
+
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x01},
+		SpanID:  trace.SpanID{0x02},
+	})
+	ctx := errors.ContextWithFields(context.Background(), errors.Fields{"request_id": "req1"})
+	ctx = trace.ContextWithSpanContext(ctx, spanCtx)
+
+	assert.Equal(t, errors.Fields{
+		"request_id": "req1",
+		KeyTraceID:   "01000000000000000000000000000000",
+		KeySpanID:    "0200000000000000",
+	}, errors.FieldsFromContext(ctx))
+	assert.Nil(t, TraceFields(context.Background()))
+}
+
+func TestAttributes_Code(t *testing.T) {
+	attrs := attribute.NewSet(Attributes(errors.WithCode(errors.New("failed"), "TEST_FAILED"))...)
+	code, _ := attrs.Value(CodeKey)
+	assert.Equal(t, "TEST_FAILED", code.AsString())
+
+	attrs = attribute.NewSet(Attributes(errors.New("failed"))...)
+	_, hasCode := attrs.Value(CodeKey)
+	assert.False(t, hasCode)
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// PanicMessage is the message of the cause created by FromPanic.
+const PanicMessage = "panic"
+
+// FromPanic converts the value recovered from a panic into NCError of ERROR severity. It must be called in the
+// deferred function, so the stack trace and the cause location point to the place the panic occurred:
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			err = errors.FromPanic(r)
+//		}
+//	}()
+//
+// Recovered errors become the root error (NCError is wrapped and keeps its stack trace), other values are formatted
+// into the message. It returns nil for nil value.
+func FromPanic(recovered interface{}, fields ...Fields) error {
+	if recovered == nil {
+		return nil
+	}
+
+	rawStack := panicStack(*callers(0))
+	var fileName, funcName string
+	var line int
+	if len(rawStack) > 0 {
+		fileName, funcName, line = frame(rawStack[0]).getContext()
+	}
+	newCause := Cause{
+		Message:  PanicMessage,
+		Fields:   mergeFields(fields...),
+		FuncName: funcName,
+		FileName: fileName,
+		Line:     line,
+		Severity: ERROR,
+	}
+
+	err, ok := recovered.(error)
+	if !ok {
+		newCause.Message = fmt.Sprintf("%s: %v", PanicMessage, recovered)
+		stack, raw := formatStackTraces(&rawStack)
+		return NCError{
+			Causes:   []Cause{newCause},
+			Stack:    stack,
+			RawStack: raw,
+		}
+	}
+
+	if ncError, wrapperCauses, ok := unwrapNCError(err); ok {
+		causes := append([]Cause{newCause}, wrapperCauses...)
+		ncError.Causes = append(causes, ncError.Causes...)
+		return ncError
+	}
+
+	stack, raw := formatStackTraces(&rawStack)
+	return NCError{
+		Causes:    []Cause{newCause, {Message: err.Error()}},
+		Stack:     stack,
+		RawStack:  raw,
+		RootError: err,
+	}
+}
+
+// panicStack returns the part of the stack below the panic, i.e. without the deferred function and the runtime
+// frames. If the stack doesn't contain the panic, it is returned as it is.
+func panicStack(st stack) stack {
+	for i, pc := range st {
+		fn := runtime.FuncForPC(frame(pc).pc())
+		if fn == nil || fn.Name() != "runtime.gopanic" {
+			continue
+		}
+
+		rest := st[i+1:]
+		for len(rest) > 1 {
+			if fn := runtime.FuncForPC(frame(rest[0]).pc()); fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+				break
+			}
+			rest = rest[1:]
+		}
+		return rest
+	}
+	return st
+}
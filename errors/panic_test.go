@@ -0,0 +1,66 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recoverPanic(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = FromPanic(r, Fields{"key": "val"})
+		}
+	}()
+
+	fn()
+	return nil
+}
+
+func panicNilMap() {
+	var m map[string]int
+	m["key"] = 1
+}
+
+func TestFromPanic(t *testing.T) {
+	assert.Nil(t, FromPanic(nil))
+
+	err := recoverPanic(func() { panic("boom") })
+	ncErr, ok := AsNC(err)
+	assert.True(t, ok)
+	assert.Equal(t, "panic: boom", err.Error())
+	assert.Equal(t, Fields{"key": "val"}, ncErr.Causes[0].Fields)
+	assert.Equal(t, ERROR, ncErr.Causes[0].Severity)
+	assert.Equal(t, "TestFromPanic.func1", ncErr.Causes[0].FuncName)
+	assert.Contains(t, ncErr.Stack[0], "panic_test.go(TestFromPanic.func1)")
+
+	err = recoverPanic(panicNilMap)
+	ncErr, _ = AsNC(err)
+	assert.Equal(t, "panic: assignment to entry in nil map", err.Error())
+	assert.Equal(t, "panicNilMap", ncErr.Causes[0].FuncName)
+	assert.Equal(t, 25, ncErr.Causes[0].Line)
+	assert.Contains(t, ncErr.Stack[0], "panic_test.go(panicNilMap):25")
+}
+
+func TestFromPanic_Error(t *testing.T) {
+	rootErr := errors.New("std")
+	err := recoverPanic(func() { panic(rootErr) })
+	assert.Equal(t, "panic: std", err.Error())
+	assert.True(t, Is(err, rootErr))
+	assert.Equal(t, rootErr, GetRootError(err))
+
+	ncErr := New("nc error")
+	err = recoverPanic(func() { panic(ncErr) })
+	assert.Equal(t, "panic: nc error", err.Error())
+	assert.Equal(t, ncErr.(NCError).Stack, err.(NCError).Stack)
+	assert.Len(t, err.(NCError).Causes, 2)
+}
+
+func TestFromPanic_NoPanic(t *testing.T) {
+	err := FromPanic("value")
+	assert.Equal(t, "panic: value", err.Error())
+	assert.Equal(t, "TestFromPanic_NoPanic", err.(NCError).Causes[0].FuncName)
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+// ProblemJSONContentType is the content type of ProblemDetails serialized to JSON.
+const ProblemJSONContentType = "application/problem+json"
+
+// ProblemDetails is the RFC 7807 problem details representation of the error. It contains only the user-facing
+// information, so it's safe to be returned in the API responses.
+type ProblemDetails struct {
+	// Type is the documentation URL of the error (see GetDocsURL). Empty value means `about:blank`.
+	Type string `json:"type,omitempty"`
+	// Title is the user-facing message of the error, see PublicMessage.
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	// Detail is the user-facing remediation hint of the error, see PublicHint.
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the error code, see GetCode.
+	Code string `json:"code,omitempty"`
+	// Owner is the team owning the error (see GetOwner), an extension member telling the API clients whom to contact.
+	Owner string `json:"owner,omitempty"`
+}
+
+// GetProblemDetails returns the problem details of the error.
+func GetProblemDetails(err error) ProblemDetails {
+	return ProblemDetails{
+		Type:   GetDocsURL(err),
+		Title:  PublicMessage(err),
+		Status: HTTPStatus(err),
+		Detail: PublicHint(err),
+		Code:   GetCode(err),
+		Owner:  GetOwner(err),
+	}
+}
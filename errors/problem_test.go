@@ -0,0 +1,33 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProblemDetails(t *testing.T) {
+	err := WithPublicMessage(NewCode(testCodeNotFound), "Project not found.", "Check the project ID.")
+	err = WithOwner(Wrap(err, "get project"), "team-projects")
+
+	assert.Equal(t, ProblemDetails{
+		Type:   "https://docs.example.com/errors/TEST_NOT_FOUND",
+		Title:  "Project not found.",
+		Status: http.StatusNotFound,
+		Detail: "Check the project ID.",
+		Code:   testCodeNotFound,
+		Owner:  "team-projects",
+	}, GetProblemDetails(err))
+	out, jsonErr := json.Marshal(GetProblemDetails(err))
+	assert.NoError(t, jsonErr)
+	assert.Contains(t, string(out), `"owner":"team-projects"`)
+
+	out, jsonErr = json.Marshal(GetProblemDetails(errors.New("std")))
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"title": "An internal error occurred. Please try again later.", "status": 500}`, string(out))
+}
@@ -0,0 +1,198 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package promerrors provides Prometheus metrics of the errors logged by the errors package.
+package promerrors
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels of the errors counter.
+const (
+	LabelCode         = "code"
+	LabelKind         = "kind"
+	LabelSeverity     = "severity"
+	LabelAWSErrorCode = "aws_error_code"
+	LabelRetryable    = "retryable"
+)
+
+const (
+	// DefaultMaxLabelValues is the default number of the distinct values of the code, kind and aws_error_code labels.
+	DefaultMaxLabelValues = 100
+	// OtherLabelValue replaces the label values exceeding the maximal number of the distinct values.
+	OtherLabelValue = "other"
+)
+
+// Option configures Metrics.
+type Option func(cfg *config)
+
+type config struct {
+	namespace      string
+	subsystem      string
+	maxLabelValues int
+	constLabels    prometheus.Labels
+}
+
+// WithNamespace sets the namespace of the metrics.
+func WithNamespace(namespace string) Option {
+	return func(cfg *config) {
+		cfg.namespace = namespace
+	}
+}
+
+// WithSubsystem sets the subsystem of the metrics.
+func WithSubsystem(subsystem string) Option {
+	return func(cfg *config) {
+		cfg.subsystem = subsystem
+	}
+}
+
+// WithMaxLabelValues sets the maximal number of the distinct values of each of the code, kind and aws_error_code
+// labels. The values seen after the limit is reached are counted as OtherLabelValue. Zero means no limit.
+func WithMaxLabelValues(n int) Option {
+	return func(cfg *config) {
+		cfg.maxLabelValues = n
+	}
+}
+
+// WithConstLabels sets the labels with constant values added to the metrics, e.g. the service name.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(cfg *config) {
+		cfg.constLabels = labels
+	}
+}
+
+// Metrics counts the logged errors. It implements prometheus.Collector, so it has to be registered in a registry,
+// e.g. prometheus.DefaultRegisterer or prometheus.NewRegistry() in tests, and enabled with Enable (see also Register).
+type Metrics struct {
+	// Errors is the `errors_logged_total` counter labelled with LabelCode, LabelKind, LabelSeverity,
+	// LabelAWSErrorCode and LabelRetryable.
+	Errors *prometheus.CounterVec
+
+	codes         *boundedValues
+	kinds         *boundedValues
+	awsErrorCodes *boundedValues
+
+	mu      sync.Mutex
+	disable func()
+}
+
+// New returns Metrics.
+func New(opts ...Option) *Metrics {
+	cfg := config{maxLabelValues: DefaultMaxLabelValues}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Metrics{
+		Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   cfg.namespace,
+			Subsystem:   cfg.subsystem,
+			Name:        "errors_logged_total",
+			Help:        "Number of the logged errors.",
+			ConstLabels: cfg.constLabels,
+		}, []string{LabelCode, LabelKind, LabelSeverity, LabelAWSErrorCode, LabelRetryable}),
+		codes:         newBoundedValues(cfg.maxLabelValues),
+		kinds:         newBoundedValues(cfg.maxLabelValues),
+		awsErrorCodes: newBoundedValues(cfg.maxLabelValues),
+	}
+}
+
+// Register creates Metrics, registers them in the registry and enables them.
+func Register(registry prometheus.Registerer, opts ...Option) (*Metrics, error) {
+	metrics := New(opts...)
+	if err := registry.Register(metrics); err != nil {
+		return nil, errors.Wrap(err, "register error metrics")
+	}
+	metrics.Enable()
+	return metrics, nil
+}
+
+// Enable starts counting the errors logged by the errors package and its adapters. Enabling enabled Metrics is
+// no-op.
+func (m *Metrics) Enable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.disable == nil {
+		m.disable = errors.AddLogObserver(m.Observe)
+	}
+}
+
+// Disable stops counting the logged errors.
+func (m *Metrics) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.disable != nil {
+		m.disable()
+		m.disable = nil
+	}
+}
+
+// Observe counts the error logged at the severity.
+func (m *Metrics) Observe(err error, severity errors.LogSeverity) {
+	if err == nil {
+		return
+	}
+
+	m.Errors.With(prometheus.Labels{
+		LabelCode:         m.codes.value(errors.GetCode(err)),
+		LabelKind:         m.kinds.value(string(errors.GetKind(err))),
+		LabelSeverity:     string(severity),
+		LabelAWSErrorCode: m.awsErrorCodes.value(errors.GetAWSErrorCode(err)),
+		LabelRetryable:    strconv.FormatBool(errors.IsRetryable(err)),
+	}).Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.Errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.Errors.Collect(ch)
+}
+
+// boundedValues bounds the number of the distinct label values.
+type boundedValues struct {
+	max int
+
+	mu     sync.RWMutex
+	values map[string]bool
+}
+
+func newBoundedValues(max int) *boundedValues {
+	return &boundedValues{max: max, values: map[string]bool{}}
+}
+
+// value returns val if it was seen before or the limit is not reached yet, OtherLabelValue otherwise. Empty value is
+// always allowed.
+func (b *boundedValues) value(val string) string {
+	if val == "" || b.max <= 0 {
+		return val
+	}
+
+	b.mu.RLock()
+	seen := b.values[val]
+	b.mu.RUnlock()
+	if seen {
+		return val
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.values[val] {
+		return val
+	}
+	if len(b.values) >= b.max {
+		return OtherLabelValue
+	}
+	b.values[val] = true
+	return val
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package promerrors
+
+import (
+	stderrors "errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	logrus.SetOutput(io.Discard)
+	registry := prometheus.NewRegistry()
+	metrics, err := Register(registry, WithNamespace("test"))
+	assert.NoError(t, err)
+	defer metrics.Disable()
+
+	errors.LogWithSeverity(errors.WithKind(errors.NewWithSeverity("not found", nil, errors.WARN), errors.KindNotFound))
+	errors.LogErrorPlain(errors.WithContext(awserr.New("Throttling", "rate exceeded", nil), "call"))
+	errors.LogError(stderrors.New("std"))
+
+	expected := `
+# HELP test_errors_logged_total Number of the logged errors.
+# TYPE test_errors_logged_total counter
+test_errors_logged_total{aws_error_code="",code="",kind="internal",retryable="false",severity="error"} 1
+test_errors_logged_total{aws_error_code="",code="",kind="not_found",retryable="false",severity="warning"} 1
+test_errors_logged_total{aws_error_code="Throttling",code="",kind="internal",retryable="false",severity="error"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+
+	_, err = Register(registry, WithNamespace("test"))
+	assert.Error(t, err)
+
+	// Disabled metrics are not counted.
+	metrics.Disable()
+	errors.LogError(stderrors.New("std"))
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
+
+func TestObserve_BoundedLabels(t *testing.T) {
+	metrics := New(WithMaxLabelValues(2))
+
+	for _, code := range []string{"CODE1", "CODE2", "CODE3", "CODE1", "CODE4"} {
+		metrics.Observe(errors.WithCode(errors.New("error"), code), errors.ERROR)
+	}
+	metrics.Observe(nil, errors.ERROR)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(metrics))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.Errors.WithLabelValues("CODE1", "internal", "error", "", "false")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.Errors.WithLabelValues(OtherLabelValue, "internal", "error", "", "false")))
+}
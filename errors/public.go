@@ -0,0 +1,239 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"context"
+	"sync"
+)
+
+// Kind classifies the error for the purpose of responding to the user, e.g. picking the HTTP status or the public
+// message.
+type Kind string
+
+// Kinds of the errors. KindInternal is used when no other kind is set.
+const (
+	KindInternal         Kind = "internal"
+	KindInvalidArgument  Kind = "invalid_argument"
+	KindNotFound         Kind = "not_found"
+	KindConflict         Kind = "conflict"
+	KindPermissionDenied Kind = "permission_denied"
+	KindUnauthenticated  Kind = "unauthenticated"
+	KindRateLimited      Kind = "rate_limited"
+	KindUnavailable      Kind = "unavailable"
+	KindTimeout          Kind = "timeout"
+	KindCanceled         Kind = "canceled"
+)
+
+var (
+	defaultPublicMessagesMu sync.RWMutex
+	defaultPublicMessages   = map[Kind]string{
+		KindInternal:         "An internal error occurred. Please try again later.",
+		KindInvalidArgument:  "The request is invalid.",
+		KindNotFound:         "The requested resource was not found.",
+		KindConflict:         "The request conflicts with the current state of the resource.",
+		KindPermissionDenied: "You do not have permission to perform this action.",
+		KindUnauthenticated:  "Authentication is required.",
+		KindRateLimited:      "Too many requests. Please try again later.",
+		KindUnavailable:      "The service is temporarily unavailable. Please try again later.",
+		KindTimeout:          "The request timed out. Please try again later.",
+		KindCanceled:         "The request was canceled.",
+	}
+)
+
+// WithCode sets the code of the outermost cause of the error. The code identifies the error, e.g. in the message
+// catalogs. Non-NCError errors are wrapped into NCError.
+func WithCode(err error, code string) error {
+	return annotate(1, err, func(cause *Cause) {
+		cause.Code = code
+	})
+}
+
+// GetCode returns the outermost code set in the error's causes or empty string.
+func GetCode(err error) string {
+	if ncError, ok := AsNC(err); ok {
+		for _, cause := range ncError.Causes {
+			if cause.Code != "" {
+				return cause.Code
+			}
+		}
+	}
+
+	return ""
+}
+
+// WithDocsURL sets the documentation (e.g. runbook) URL of the outermost cause of the error. Non-NCError errors are
+// wrapped into NCError.
+func WithDocsURL(err error, url string) error {
+	return annotate(1, err, func(cause *Cause) {
+		cause.DocsURL = url
+	})
+}
+
+// WithOwner sets the team owning the outermost cause of the error. Non-NCError errors are wrapped into NCError.
+func WithOwner(err error, owner string) error {
+	return annotate(1, err, func(cause *Cause) {
+		cause.Owner = owner
+	})
+}
+
+// GetDocsURL returns the outermost documentation URL of the error. For every cause the URL set with WithDocsURL is
+// taken first and then the one of the registered cause code.
+func GetDocsURL(err error) string {
+	return findCauseAttribute(err, func(cause Cause, info CodeInfo) string {
+		if cause.DocsURL != "" {
+			return cause.DocsURL
+		}
+		return info.DocsURL
+	})
+}
+
+// GetOwner returns the outermost team owning the error. For every cause the owner set with WithOwner is taken first
+// and then the one of the registered cause code.
+func GetOwner(err error) string {
+	return findCauseAttribute(err, func(cause Cause, info CodeInfo) string {
+		if cause.Owner != "" {
+			return cause.Owner
+		}
+		return info.Owner
+	})
+}
+
+// WithKind sets the kind of the outermost cause of the error. Non-NCError errors are wrapped into NCError.
+func WithKind(err error, kind Kind) error {
+	return annotate(1, err, func(cause *Cause) {
+		cause.Kind = kind
+	})
+}
+
+// WithPublicMessage sets the user-facing message and optional remediation hint of the outermost cause of the error.
+// Non-NCError errors are wrapped into NCError. Unlike the cause messages, the public message is not included in the
+// error message, so it can be written for the end user.
+func WithPublicMessage(err error, message string, hint ...string) error {
+	return annotate(1, err, func(cause *Cause) {
+		cause.PublicMessage = message
+		if len(hint) > 0 {
+			cause.Hint = hint[0]
+		}
+	})
+}
+
+// GetKind returns the outermost kind set in the error's causes. Errors caused by context cancellation or deadline
+// are KindCanceled and KindTimeout respectively, all the other errors are KindInternal.
+func GetKind(err error) Kind {
+	if ncError, ok := AsNC(err); ok {
+		for _, cause := range ncError.Causes {
+			if cause.Kind != "" {
+				return cause.Kind
+			}
+		}
+	}
+
+	switch {
+	case Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case Is(err, context.Canceled):
+		return KindCanceled
+	default:
+		return KindInternal
+	}
+}
+
+// PublicMessage returns the outermost user-facing message set in the error's causes. If none is set, the generic
+// message of the error kind is returned (see SetDefaultPublicMessage), so the internal details never leak.
+func PublicMessage(err error) string {
+	if ncError, ok := AsNC(err); ok {
+		for _, cause := range ncError.Causes {
+			if cause.PublicMessage != "" {
+				return cause.PublicMessage
+			}
+		}
+	}
+
+	return DefaultPublicMessage(GetKind(err))
+}
+
+// PublicHint returns the outermost user-facing remediation hint set in the error's causes or empty string.
+func PublicHint(err error) string {
+	if ncError, ok := AsNC(err); ok {
+		for _, cause := range ncError.Causes {
+			if cause.Hint != "" {
+				return cause.Hint
+			}
+		}
+	}
+
+	return ""
+}
+
+// DefaultPublicMessage returns the generic user-facing message of the kind. Unknown kinds fall back to the
+// KindInternal message.
+func DefaultPublicMessage(kind Kind) string {
+	defaultPublicMessagesMu.RLock()
+	defer defaultPublicMessagesMu.RUnlock()
+
+	if message, ok := defaultPublicMessages[kind]; ok {
+		return message
+	}
+	return defaultPublicMessages[KindInternal]
+}
+
+// SetDefaultPublicMessage sets the generic user-facing message of the kind.
+func SetDefaultPublicMessage(kind Kind, message string) {
+	defaultPublicMessagesMu.Lock()
+	defer defaultPublicMessagesMu.Unlock()
+
+	defaultPublicMessages[kind] = message
+}
+
+// findCauseAttribute returns the first non-empty attribute of the error's causes, from the outermost one.
+func findCauseAttribute(err error, attribute func(cause Cause, info CodeInfo) string) string {
+	ncError, ok := AsNC(err)
+	if !ok {
+		return ""
+	}
+
+	for _, cause := range ncError.Causes {
+		info, _ := LookupCode(cause.Code)
+		if val := attribute(cause, info); val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+// annotate applies fn to the outermost cause of the NCError found in the err chain. Non-NCError errors are wrapped
+// into NCError with a single cause located at the caller. The skip is the number of the frames between annotate and
+// the caller to be recorded.
+func annotate(skip int, err error, fn func(cause *Cause)) error {
+	if err == nil {
+		return nil
+	}
+
+	if ncError, wrapperCauses, ok := unwrapNCError(err); ok {
+		causes := append(wrapperCauses, ncError.Causes...)
+		if len(causes) == 0 {
+			causes = []Cause{{Severity: ERROR}}
+		}
+		fn(&causes[0])
+		ncError.Causes = causes
+		return ncError
+	}
+
+	fileName, funcName, lineNumber := getRuntimeContextSkip(skip)
+	newCause := Cause{
+		Message:  err.Error(),
+		FuncName: funcName,
+		FileName: fileName,
+		Line:     lineNumber,
+		Severity: ERROR,
+	}
+	fn(&newCause)
+	stack, rawStack := getStackTracesSkip(skip)
+	return NCError{
+		Causes:    []Cause{newCause},
+		Stack:     stack,
+		RawStack:  rawStack,
+		RootError: err,
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicMessage(t *testing.T) {
+	err := New("db row not found", Fields{"id": 1})
+	assert.Equal(t, DefaultPublicMessage(KindInternal), PublicMessage(err))
+
+	err = WithKind(err, KindNotFound)
+	assert.Equal(t, KindNotFound, GetKind(err))
+	assert.Equal(t, "The requested resource was not found.", PublicMessage(err))
+
+	err = WithPublicMessage(err, "The project does not exist.", "Check the project ID.")
+	err = Wrap(err, "get project")
+	assert.Equal(t, "The project does not exist.", PublicMessage(err))
+	assert.Equal(t, "Check the project ID.", PublicHint(err))
+
+	err = WithPublicMessage(err, "The project could not be loaded.")
+	assert.Equal(t, "The project could not be loaded.", PublicMessage(err))
+	assert.Equal(t, "Check the project ID.", PublicHint(err))
+
+	// The public message is not a part of the error message.
+	assert.Equal(t, "get project: db row not found", err.Error())
+	assert.Len(t, err.(NCError).Causes, 2)
+}
+
+func TestPublicMessage_StandardError(t *testing.T) {
+	assert.Equal(t, DefaultPublicMessage(KindInternal), PublicMessage(errors.New("std")))
+	assert.Equal(t, "", PublicHint(errors.New("std")))
+	assert.Nil(t, WithPublicMessage(nil, "message"))
+
+	rootErr := errors.New("std")
+	err := WithPublicMessage(rootErr, "message", "hint")
+	assert.Equal(t, "std", err.Error())
+	assert.Equal(t, rootErr, GetRootError(err))
+	assert.Equal(t, ERROR, GetErrorSeverity(err))
+	assert.Equal(t, "message", PublicMessage(err))
+
+	err = WithKind(fmt.Errorf("std wrap: %w", New("nc error")), KindConflict)
+	assert.Equal(t, "std wrap: nc error", err.Error())
+	assert.Equal(t, KindConflict, GetKind(err))
+}
+
+func TestGetKind_Context(t *testing.T) {
+	assert.Equal(t, KindTimeout, GetKind(Wrap(context.DeadlineExceeded, "call")))
+	assert.Equal(t, KindCanceled, GetKind(fmt.Errorf("call: %w", context.Canceled)))
+	assert.Equal(t, KindInternal, GetKind(errors.New("std")))
+}
+
+func TestSetDefaultPublicMessage(t *testing.T) {
+	defer SetDefaultPublicMessage(KindConflict, DefaultPublicMessage(KindConflict))
+	SetDefaultPublicMessage(KindConflict, "Conflict.")
+
+	assert.Equal(t, "Conflict.", PublicMessage(WithKind(errors.New("std"), KindConflict)))
+	assert.Equal(t, DefaultPublicMessage(KindInternal), DefaultPublicMessage(Kind("unknown")))
+}
+
+func TestCode(t *testing.T) {
+	assert.Equal(t, "", GetCode(errors.New("std")))
+
+	err := WithCode(New("error1"), "ERR_INNER")
+	assert.Equal(t, "ERR_INNER", GetCode(Wrap(err, "level2")))
+
+	err = WithCode(Wrap(err, "level2"), "ERR_OUTER")
+	assert.Equal(t, "ERR_OUTER", GetCode(fmt.Errorf("std wrap: %w", err)))
+}
+
+func TestDocsURLAndOwner(t *testing.T) {
+	assert.Equal(t, "", GetDocsURL(errors.New("std")))
+	assert.Equal(t, "", GetOwner(New("error")))
+
+	err := NewCode(testCodeNotFound)
+	assert.Equal(t, "https://docs.example.com/errors/TEST_NOT_FOUND", GetDocsURL(Wrap(err, "level2")))
+
+	err = WithOwner(WithDocsURL(Wrap(err, "level2"), "https://runbooks.example.com/level2"), "team-a")
+	err = fmt.Errorf("std wrap: %w", Wrap(err, "level3"))
+	assert.Equal(t, "https://runbooks.example.com/level2", GetDocsURL(err))
+	assert.Equal(t, "team-a", GetOwner(err))
+}
+
+func TestWithKind_Caller(t *testing.T) {
+	err := WithKind(errors.New("std"), KindNotFound).(NCError)
+
+	assert.Equal(t, "TestWithKind_Caller", err.Causes[0].FuncName)
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/public_test.go", err.Causes[0].FileName)
+	assert.NotZero(t, err.Causes[0].Line)
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/public.go(WithKind)", frameNames(err.Stack)[0])
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/public_test.go(TestWithKind_Caller)", frameNames(err.Stack)[1])
+
+	err = WithFields(errors.New("std"), Fields{"id": 1}).(NCError)
+	assert.Equal(t, "TestWithKind_Caller", err.Causes[0].FuncName)
+}
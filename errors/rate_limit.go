@@ -0,0 +1,230 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SuppressedKey holds the number of the occurrences suppressed by RateLimiter in the summary log entries.
+const SuppressedKey = "error_suppressed"
+
+var rateLimiter atomic.Pointer[RateLimiter]
+
+// RateLimit bounds how often the same error is logged.
+type RateLimit struct {
+	// Burst is the number of the occurrences logged per Window. The other occurrences are suppressed and reported
+	// in a summary.
+	Burst int
+	// Window is the period the occurrences are counted in. Zero Window disables the limit.
+	Window time.Duration
+}
+
+// SummaryFunc logs the summary of the occurrences of the error suppressed by RateLimiter at the severity. err is the
+// last suppressed occurrence and message is the summary message, already redacted.
+type SummaryFunc func(err error, severity LogSeverity, suppressed int, message string)
+
+// RateLimiterOption configures RateLimiter.
+type RateLimiterOption func(l *RateLimiter)
+
+// WithSeverityRateLimit sets the limit of the errors logged at the severity.
+func WithSeverityRateLimit(severity LogSeverity, limit RateLimit) RateLimiterOption {
+	return func(l *RateLimiter) {
+		l.limits[severity] = limit
+	}
+}
+
+// WithRateLimitKey sets the function grouping the occurrences of the same error. Fingerprint is used by default,
+// see also RateLimitByCode.
+func WithRateLimitKey(key func(err error) string) RateLimiterOption {
+	return func(l *RateLimiter) {
+		l.key = key
+	}
+}
+
+// RateLimitByCode groups the errors by their code (see GetCode). Errors without code are grouped by Fingerprint.
+func RateLimitByCode(err error) string {
+	if code := GetCode(err); code != "" {
+		return "code:" + code
+	}
+	return defaultRateLimitKey(err)
+}
+
+func defaultRateLimitKey(err error) string {
+	return Fingerprint(err, getFingerprintOptions()...)
+}
+
+// RateLimiter suppresses repeated logging of the same error. The first RateLimit.Burst occurrences are logged in
+// every RateLimit.Window, the rest is counted and logged as a single summary entry with the fields of the last
+// suppressed occurrence. The summary is logged with the first occurrence of the next window or by Flush. Use
+// SetRateLimiter to enable it for the logging functions of this package.
+type RateLimiter struct {
+	limits map[LogSeverity]RateLimit
+	key    func(err error) string
+	now    func() time.Time
+
+	mu        sync.Mutex
+	entries   map[rateLimitKey]*rateLimitEntry
+	lastSweep time.Time
+}
+
+type rateLimitKey struct {
+	key      string
+	severity LogSeverity
+}
+
+type rateLimitEntry struct {
+	windowStart time.Time
+	window      time.Duration
+	count       int
+	suppressed  int
+	lastErr     error
+	summary     SummaryFunc
+}
+
+type suppressedSummary struct {
+	severity LogSeverity
+	entry    rateLimitEntry
+}
+
+// NewRateLimiter returns RateLimiter applying the limit to all the severities unless overridden with
+// WithSeverityRateLimit.
+func NewRateLimiter(limit RateLimit, opts ...RateLimiterOption) *RateLimiter {
+	l := &RateLimiter{
+		limits: map[LogSeverity]RateLimit{
+			ERROR: limit,
+			WARN:  limit,
+			INFO:  limit,
+			DEBUG: limit,
+		},
+		key:     defaultRateLimitKey,
+		now:     time.Now,
+		entries: map[rateLimitKey]*rateLimitEntry{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// SetRateLimiter sets RateLimiter used by the logging functions of this package. Nil disables the rate limiting.
+func SetRateLimiter(limiter *RateLimiter) {
+	rateLimiter.Store(limiter)
+}
+
+// GetRateLimiter returns RateLimiter used by the logging functions of this package or nil.
+func GetRateLimiter() *RateLimiter {
+	return rateLimiter.Load()
+}
+
+// AllowLog registers the occurrence of the error logged at the severity with the configured RateLimiter (see
+// SetRateLimiter) and reports whether it should be logged. It always returns true if no RateLimiter is set. summary
+// logs the summary of the suppressed occurrences, see RateLimiter.Allow. Use it in the adapters for other logging
+// libraries.
+func AllowLog(err error, severity LogSeverity, summary SummaryFunc) bool {
+	limiter := GetRateLimiter()
+	return limiter == nil || limiter.Allow(err, severity, summary)
+}
+
+// Flush logs the summaries of the windows which have ended and forgets them.
+func (l *RateLimiter) Flush() {
+	l.mu.Lock()
+	summaries := l.expire(l.now())
+	l.mu.Unlock()
+
+	for _, summary := range summaries {
+		summary.log()
+	}
+}
+
+// expire forgets the entries of the windows which have ended and returns their summaries to be logged. It must be
+// called with the mutex held.
+func (l *RateLimiter) expire(now time.Time) []suppressedSummary {
+	var summaries []suppressedSummary
+	for key, entry := range l.entries {
+		if now.Sub(entry.windowStart) < entry.window {
+			continue
+		}
+		if entry.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{severity: key.severity, entry: *entry})
+		}
+		delete(l.entries, key)
+	}
+	l.lastSweep = now
+	return summaries
+}
+
+// Run calls Flush every interval until the context is done, so the summaries are logged even if the error stops
+// occurring.
+func (l *RateLimiter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Flush()
+		}
+	}
+}
+
+// Allow registers the occurrence of the error logged at the severity and reports whether it should be logged.
+// summary logs the summary of the suppressed occurrences, it is called by Allow or Flush with the last summary set for
+// the error. The ended windows are expired at most once per the severity's window, so the entries don't accumulate
+// even without Run.
+func (l *RateLimiter) Allow(err error, severity LogSeverity, summary SummaryFunc) bool {
+	limit := l.limits[severity]
+	if limit.Window <= 0 {
+		return true
+	}
+
+	key := rateLimitKey{key: l.key(err), severity: severity}
+	now := l.now()
+	var summaries []suppressedSummary
+
+	l.mu.Lock()
+	if now.Sub(l.lastSweep) >= limit.Window {
+		summaries = l.expire(now)
+	}
+	entry, ok := l.entries[key]
+	if !ok || now.Sub(entry.windowStart) >= entry.window {
+		if ok && entry.suppressed > 0 {
+			summaries = append(summaries, suppressedSummary{severity: severity, entry: *entry})
+		}
+		entry = &rateLimitEntry{windowStart: now, window: limit.Window}
+		l.entries[key] = entry
+	}
+	entry.count++
+	allowed := entry.count <= limit.Burst
+	if !allowed {
+		entry.suppressed++
+		entry.lastErr = err
+		entry.summary = summary
+	}
+	l.mu.Unlock()
+
+	for _, summary := range summaries {
+		summary.log()
+	}
+	return allowed
+}
+
+func (s suppressedSummary) log() {
+	s.entry.summary(s.entry.lastErr, s.severity, s.entry.suppressed, fmt.Sprintf("suppressed %d occurrences in %s: %s",
+		s.entry.suppressed, s.entry.window, RedactMessage(s.entry.lastErr.Error())))
+}
+
+// logrusSummary returns SummaryFunc logging the summary with the entry built by newEntry.
+func logrusSummary(newEntry func(err error) *logrus.Entry) SummaryFunc {
+	return func(err error, severity LogSeverity, suppressed int, message string) {
+		newEntry(err).WithField(SuppressedKey, suppressed).Log(logrusLevel(severity), message)
+	}
+}
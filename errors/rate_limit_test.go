@@ -0,0 +1,157 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func newTestRateLimiter(limit RateLimit, opts ...RateLimiterOption) (*RateLimiter, *testClock) {
+	clock := &testClock{now: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(limit, opts...)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+func TestRateLimiter(t *testing.T) {
+	hook := test.NewGlobal()
+	limiter, clock := newTestRateLimiter(RateLimit{Burst: 2, Window: time.Minute})
+	SetRateLimiter(limiter)
+	defer SetRateLimiter(nil)
+
+	// The errors are created at the same place, so they have the same fingerprint.
+	for i := 0; i < 6; i++ {
+		if i == 5 {
+			assert.Len(t, hook.AllEntries(), 2)
+			// The summary is logged with the first occurrence in the next window.
+			clock.now = clock.now.Add(time.Minute)
+		}
+		LogError(New("dependency unavailable", Fields{"id": i}))
+	}
+	entries := hook.AllEntries()
+	assert.Len(t, entries, 4)
+	summary := entries[2]
+	assert.Equal(t, logrus.ErrorLevel, summary.Level)
+	assert.Equal(t, "suppressed 3 occurrences in 1m0s: dependency unavailable", summary.Message)
+	assert.Equal(t, 3, summary.Data[SuppressedKey])
+	assert.Equal(t, Fields{"id": 4}, summary.Data[errorCtxKey].(Fields)["causes"].([]Cause)[0].Fields)
+	assert.Equal(t, "dependency unavailable", entries[3].Message)
+}
+
+func TestRateLimiter_Flush(t *testing.T) {
+	hook := test.NewGlobal()
+	limiter, clock := newTestRateLimiter(RateLimit{Burst: 1, Window: time.Minute})
+	SetRateLimiter(limiter)
+	defer SetRateLimiter(nil)
+
+	for i := 0; i < 3; i++ {
+		LogWarningPlain(New("error", Fields{"id": i}))
+	}
+	limiter.Flush()
+	assert.Len(t, hook.AllEntries(), 1)
+
+	clock.now = clock.now.Add(time.Minute)
+	limiter.Flush()
+	assert.Len(t, hook.AllEntries(), 2)
+	summary := hook.LastEntry()
+	assert.Equal(t, logrus.WarnLevel, summary.Level)
+	assert.Equal(t, 2, summary.Data[SuppressedKey])
+	assert.Equal(t, 2, summary.Data["id"])
+	assert.Empty(t, limiter.entries)
+}
+
+func TestRateLimiter_SeverityAndKey(t *testing.T) {
+	hook := test.NewGlobal()
+	limiter, _ := newTestRateLimiter(RateLimit{Burst: 1, Window: time.Minute},
+		WithSeverityRateLimit(WARN, RateLimit{}),
+		WithRateLimitKey(RateLimitByCode))
+	SetRateLimiter(limiter)
+	defer SetRateLimiter(nil)
+
+	for i := 0; i < 3; i++ {
+		LogWarning(New("not limited"))
+	}
+	assert.Len(t, hook.AllEntries(), 3)
+
+	hook.Reset()
+	LogError(WithCode(New("error1"), "CODE"))
+	LogError(WithCode(New("error2"), "CODE"))
+	LogError(WithCode(New("error3"), "OTHER"))
+	assert.Len(t, hook.AllEntries(), 2)
+}
+
+func TestRateLimitByCode(t *testing.T) {
+	assert.Equal(t, "code:CODE", RateLimitByCode(WithCode(New("error"), "CODE")))
+	err := New("error")
+	assert.Equal(t, Fingerprint(err), RateLimitByCode(err))
+}
+
+func TestRateLimiter_Eviction(t *testing.T) {
+	hook := test.NewGlobal()
+	limiter, clock := newTestRateLimiter(RateLimit{Burst: 1, Window: time.Minute},
+		WithRateLimitKey(func(err error) string { return err.Error() }))
+	SetRateLimiter(limiter)
+	defer SetRateLimiter(nil)
+
+	LogError(New("error1"))
+	LogError(New("error1"))
+	LogError(New("error2"))
+	assert.Len(t, limiter.entries, 2)
+
+	// The ended windows are expired by the next occurrence of any error, without Flush.
+	clock.now = clock.now.Add(time.Minute)
+	LogError(New("error3"))
+	assert.Len(t, limiter.entries, 1)
+	assert.Equal(t, "suppressed 1 occurrences in 1m0s: error1", hook.AllEntries()[2].Message)
+	assert.Equal(t, "error3", hook.LastEntry().Message)
+}
+
+func TestRateLimiter_Report(t *testing.T) {
+	test.NewGlobal()
+	limiter, _ := newTestRateLimiter(RateLimit{Burst: 1, Window: time.Minute})
+	SetRateLimiter(limiter)
+	defer SetRateLimiter(nil)
+	reporter := &testReporter{}
+	SetReporter(reporter)
+	defer SetReporter(nil)
+
+	for i := 0; i < 3; i++ {
+		LogWithSeverity(New("error", Fields{"id": i}))
+	}
+	assert.Len(t, reporter.reported, 1)
+}
+
+func TestAllowLog(t *testing.T) {
+	assert.True(t, AllowLog(New("error"), ERROR, nil))
+
+	limiter, clock := newTestRateLimiter(RateLimit{Burst: 1, Window: time.Minute})
+	SetRateLimiter(limiter)
+	defer SetRateLimiter(nil)
+
+	var summaries []string
+	summary := func(err error, severity LogSeverity, suppressed int, message string) {
+		assert.Equal(t, WARN, severity)
+		assert.Equal(t, 2, suppressed)
+		summaries = append(summaries, message)
+	}
+	var allowed []bool
+	for i := 0; i < 3; i++ {
+		allowed = append(allowed, AllowLog(New("error", Fields{"id": i}), WARN, summary))
+	}
+	assert.Equal(t, []bool{true, false, false}, allowed)
+
+	clock.now = clock.now.Add(time.Minute)
+	limiter.Flush()
+	assert.Equal(t, []string{"suppressed 2 occurrences in 1m0s: error"}, summaries)
+}
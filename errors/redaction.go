@@ -0,0 +1,326 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// RedactedValue replaces the sensitive values in the logs.
+const RedactedValue = "[REDACTED]"
+
+var (
+	// DefaultRedactedKeys is the deny-list of field keys used by the default Redactor.
+	DefaultRedactedKeys = []string{
+		"password", "passwd", "secret", "token", "api_key", "secret_access_key", "session_token", "private_key",
+		"authorization", "cookie", "credentials", "request_body", "response_body",
+	}
+	// DefaultRedactedPatterns is the list of value patterns used by the default Redactor. They match AWS access key
+	// IDs, JWTs and email addresses.
+	DefaultRedactedPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`),
+		regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]*`),
+		regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	}
+)
+
+var redactor atomic.Pointer[Redactor]
+
+func init() {
+	SetRedactor(NewRedactor(DefaultRedactedKeys, DefaultRedactedPatterns))
+}
+
+// Secret wraps a sensitive value. Its formatted (with any verb), String and JSON representations are masked, so it's
+// safe to put into Fields. Use string(secret) to get the value.
+type Secret string
+
+// String returns RedactedValue.
+func (s Secret) String() string {
+	return RedactedValue
+}
+
+// GoString returns RedactedValue.
+func (s Secret) GoString() string {
+	return RedactedValue
+}
+
+// Format implements fmt.Formatter. It prints RedactedValue for any verb, quoted for `%q`.
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		_, _ = f.Write([]byte(strconv.Quote(RedactedValue)))
+		return
+	}
+	_, _ = f.Write([]byte(RedactedValue))
+}
+
+// MarshalJSON returns RedactedValue as JSON string.
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + RedactedValue + `"`), nil
+}
+
+// MarshalText returns RedactedValue.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(RedactedValue), nil
+}
+
+// Redactor masks sensitive values of the Fields. The value is masked if its key is on the deny-list or it's a Secret.
+// Parts of string values matching any of the patterns are masked too.
+//
+// Keys are matched case-insensitively ignoring `_`, `-` and `.` separators, so `secret_access_key` matches
+// `SecretAccessKey` too. The key matches also if it ends with the deny-listed key, e.g. `db_password`.
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns Redactor with the given deny-list of keys and value patterns.
+func NewRedactor(keys []string, patterns []*regexp.Regexp) *Redactor {
+	normalizedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		normalizedKeys = append(normalizedKeys, normalizeKey(key))
+	}
+
+	return &Redactor{keys: normalizedKeys, patterns: patterns}
+}
+
+// SetRedactor sets Redactor used by all the logging functions and serializers of this package. Pass nil to disable
+// redaction.
+func SetRedactor(r *Redactor) {
+	redactor.Store(r)
+}
+
+// GetRedactor returns Redactor used by all the logging functions and serializers of this package.
+func GetRedactor() *Redactor {
+	return redactor.Load()
+}
+
+// RedactFields returns a copy of fields with sensitive values masked by the configured Redactor.
+func RedactFields(fields Fields) Fields {
+	return GetRedactor().Fields(fields)
+}
+
+// RedactMessage returns the message with the parts matching the patterns of the configured Redactor masked.
+func RedactMessage(message string) string {
+	return GetRedactor().Message(message)
+}
+
+// Message returns the message with the parts matching any of the patterns masked.
+func (r *Redactor) Message(message string) string {
+	if r == nil {
+		return message
+	}
+	return r.redactString(message)
+}
+
+// Fields returns a copy of fields with sensitive values masked. Nested Fields and maps are redacted recursively.
+func (r *Redactor) Fields(fields Fields) Fields {
+	if r == nil || fields == nil {
+		return fields
+	}
+
+	redacted := make(Fields, len(fields))
+	for k, v := range fields {
+		redacted[k] = r.Value(k, v)
+	}
+	return redacted
+}
+
+// Value returns the value stored under the key with sensitive data masked. Slices, arrays and maps are redacted
+// recursively (map entries by their keys), the structs by their exported fields' names. The errors are replaced by
+// their redacted messages. Values which contain anything to mask are returned as []interface{},
+// map[string]interface{} or Fields (for structs), other values are returned as they are.
+func (r *Redactor) Value(key string, val interface{}) interface{} {
+	if r == nil {
+		return val
+	}
+	if r.isDenied(key) {
+		return RedactedValue
+	}
+
+	switch v := val.(type) {
+	case Secret:
+		return RedactedValue
+	case string:
+		return r.redactString(v)
+	case []string:
+		redacted := make([]string, len(v))
+		for i, s := range v {
+			redacted[i] = r.redactString(s)
+		}
+		return redacted
+	case Fields:
+		return r.Fields(v)
+	case map[string]interface{}:
+		return map[string]interface{}(r.Fields(v))
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for k, s := range v {
+			if r.isDenied(k) {
+				redacted[k] = RedactedValue
+			} else {
+				redacted[k] = r.redactString(s)
+			}
+		}
+		return redacted
+	case []FieldConflict:
+		return r.conflicts(key, v)
+	case causeConflicts:
+		redacted := make(causeConflicts, len(v))
+		for k, conflicts := range v {
+			redacted[k] = r.conflicts(k, conflicts)
+		}
+		return redacted
+	default:
+		redacted, _ := r.reflectValue(key, reflect.ValueOf(val), map[visitKey]bool{})
+		return redacted
+	}
+}
+
+func (r *Redactor) conflicts(key string, conflicts []FieldConflict) []FieldConflict {
+	redacted := make([]FieldConflict, len(conflicts))
+	for i, conflict := range conflicts {
+		conflict.Value = r.Value(key, conflict.Value)
+		redacted[i] = conflict
+	}
+	return redacted
+}
+
+// reflectValue redacts the value of any other type than handled by Value. It returns false if there was nothing to
+// mask, then the value is returned as it is. The visited holds the references on the path to the value to stop at
+// the cycles.
+func (r *Redactor) reflectValue(key string, v reflect.Value, visited map[visitKey]bool) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	if r.isDenied(key) {
+		return RedactedValue, true
+	}
+	val := v.Interface()
+	switch typed := val.(type) {
+	case Secret:
+		return RedactedValue, true
+	case string:
+		redacted := r.redactString(typed)
+		return redacted, redacted != typed
+	case error:
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return val, false
+		}
+		message := typed.Error()
+		redacted := r.redactString(message)
+		if redacted == message {
+			return val, false
+		}
+		return redacted, true
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return val, false
+		}
+		ref := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if visited[ref] {
+			return val, false
+		}
+		visited[ref] = true
+		defer delete(visited, ref)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return val, false
+		}
+		if redacted, changed := r.reflectValue(key, v.Elem(), visited); changed {
+			return redacted, true
+		}
+	case reflect.Slice, reflect.Array:
+		redacted := make([]interface{}, v.Len())
+		changed := false
+		for i := 0; i < v.Len(); i++ {
+			var elemChanged bool
+			redacted[i], elemChanged = r.reflectValue(key, v.Index(i), visited)
+			changed = changed || elemChanged
+		}
+		if changed {
+			return redacted, true
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return val, false
+		}
+		redacted := make(map[string]interface{}, v.Len())
+		changed := false
+		iter := v.MapRange()
+		for iter.Next() {
+			var elemChanged bool
+			k := iter.Key().String()
+			redacted[k], elemChanged = r.reflectValue(k, iter.Value(), visited)
+			changed = changed || elemChanged
+		}
+		if changed {
+			return redacted, true
+		}
+	case reflect.Struct:
+		redacted := Fields{}
+		changed := false
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			var fieldChanged bool
+			redacted[field.Name], fieldChanged = r.reflectValue(field.Name, v.Field(i), visited)
+			changed = changed || fieldChanged
+		}
+		if changed {
+			return redacted, true
+		}
+	}
+
+	return val, false
+}
+
+func (r *Redactor) isDenied(key string) bool {
+	normalized := normalizeKey(key)
+	for _, k := range r.keys {
+		if strings.HasSuffix(normalized, k) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) redactString(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, RedactedValue)
+	}
+	return s
+}
+
+// redactNCError returns a copy of ncError with the causes' messages and fields redacted by the configured Redactor.
+func redactNCError(ncError NCError) NCError {
+	r := GetRedactor()
+	if r == nil {
+		return ncError
+	}
+
+	causes := make([]Cause, len(ncError.Causes))
+	for i, cause := range ncError.Causes {
+		cause.Message = r.Message(cause.Message)
+		cause.Fields = r.Fields(cause.Fields)
+		causes[i] = cause
+	}
+	ncError.Causes = causes
+	return ncError
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(key))
+}
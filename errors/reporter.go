@@ -0,0 +1,37 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import "sync/atomic"
+
+// Reporter reports errors to an error tracking service, e.g. Sentry.
+type Reporter interface {
+	Report(err error)
+}
+
+var reporter atomic.Pointer[Reporter]
+
+// SetReporter sets Reporter used by Report. Nil disables the reporting.
+func SetReporter(r Reporter) {
+	if r == nil {
+		reporter.Store(nil)
+		return
+	}
+	reporter.Store(&r)
+}
+
+// GetReporter returns Reporter used by Report or nil.
+func GetReporter() Reporter {
+	if r := reporter.Load(); r != nil {
+		return *r
+	}
+	return nil
+}
+
+// Report reports the error with the configured Reporter (see SetReporter). LogWithSeverity reports the errors of
+// ERROR severity automatically.
+func Report(err error) {
+	if r := GetReporter(); r != nil && err != nil {
+		r.Report(err)
+	}
+}
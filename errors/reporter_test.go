@@ -0,0 +1,40 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package errors
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testReporter struct {
+	reported []error
+}
+
+func (r *testReporter) Report(err error) {
+	r.reported = append(r.reported, err)
+}
+
+func TestReport(t *testing.T) {
+	Report(New("no reporter"))
+
+	reporter := &testReporter{}
+	SetReporter(reporter)
+	defer SetReporter(nil)
+	assert.Equal(t, reporter, GetReporter())
+
+	errError := New("error")
+	errStd := errors.New("std")
+	LogWithSeverity(NewWithSeverity("warning", nil, WARN))
+	LogWithSeverity(errError)
+	LogWithSeverity(errStd)
+	LogError(New("not reported"))
+	Report(nil)
+
+	assert.Equal(t, []error{errError, errStd}, reporter.reported)
+
+	SetReporter(nil)
+	assert.Nil(t, GetReporter())
+}
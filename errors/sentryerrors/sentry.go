@@ -0,0 +1,172 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package sentryerrors provides Sentry reporter for NCError.
+package sentryerrors
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/nordcloud/ncerrors/errors"
+)
+
+// Tags set on the events.
+const (
+	TagCode         = "error.code"
+	TagSeverity     = "error.severity"
+	TagAWSErrorCode = "error.aws_error_code"
+)
+
+// ncErrorType is the exception type of the causes without code.
+const ncErrorType = "NCError"
+
+// Option configures Reporter.
+type Option func(r *Reporter)
+
+// WithInAppPrefixes marks only the stack frames of the functions with any of the package path prefixes as in-app,
+// see errors.IsInAppFrame.
+func WithInAppPrefixes(prefixes ...string) Option {
+	return func(r *Reporter) {
+		r.inAppPrefixes = append(r.inAppPrefixes, prefixes...)
+	}
+}
+
+// Reporter implements errors.Reporter sending the errors to Sentry. Use
+// `errors.SetReporter(sentryerrors.NewReporter(nil))` to report the errors logged with errors.LogWithSeverity.
+type Reporter struct {
+	hub           *sentry.Hub
+	inAppPrefixes []string
+}
+
+// NewReporter returns Reporter sending the events with the hub. Nil hub means sentry.CurrentHub().
+func NewReporter(hub *sentry.Hub, opts ...Option) *Reporter {
+	if hub == nil {
+		hub = sentry.CurrentHub()
+	}
+
+	r := &Reporter{hub: hub}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Report sends the error to Sentry.
+func (r *Reporter) Report(err error) {
+	if err == nil {
+		return
+	}
+	r.hub.CaptureEvent(r.Event(err))
+}
+
+// Event converts the error into sentry.Event. The exceptions are built from the root error and the causes (from the
+// innermost one), the stack trace is attached to the outermost exception. The event is tagged with the error code,
+// severity and AWS error code and the merged fields are set as extras. The fields are redacted and bounded the same
+// way as in the logs.
+func (r *Reporter) Event(err error) *sentry.Event {
+	details := errors.GetLogDetails(err)
+
+	event := sentry.NewEvent()
+	event.Level = Level(details.Severity)
+	event.Message = details.Message
+	event.Tags[TagSeverity] = string(details.Severity)
+	if code := errors.GetCode(err); code != "" {
+		event.Tags[TagCode] = code
+	}
+	if details.AWSError != nil {
+		event.Tags[TagAWSErrorCode] = details.AWSError.Code()
+	}
+	if details.Fingerprint != "" {
+		event.Fingerprint = []string{details.Fingerprint}
+	}
+
+	if details.NCError == nil {
+		event.Exception = []sentry.Exception{{Type: fmt.Sprintf("%T", err), Value: details.Message}}
+		return event
+	}
+
+	for k, v := range details.NCError.GetMergedFields() {
+		event.Extra[k] = v
+	}
+	event.Exception = exceptions(*details.NCError)
+	event.Exception[len(event.Exception)-1].Stacktrace = r.stacktrace(*details.NCError)
+	return event
+}
+
+// Level converts LogSeverity into sentry.Level. Unknown severities are mapped to sentry.LevelError.
+func Level(severity errors.LogSeverity) sentry.Level {
+	switch severity {
+	case errors.WARN:
+		return sentry.LevelWarning
+	case errors.INFO:
+		return sentry.LevelInfo
+	case errors.DEBUG:
+		return sentry.LevelDebug
+	default:
+		return sentry.LevelError
+	}
+}
+
+// exceptions returns the exceptions of the root error and the causes ordered from the innermost one, as expected by
+// Sentry.
+func exceptions(ncError errors.NCError) []sentry.Exception {
+	var result []sentry.Exception
+	rootMessage := ""
+	if ncError.RootError != nil {
+		rootMessage = errors.RedactMessage(ncError.RootError.Error())
+		result = append(result, sentry.Exception{Type: fmt.Sprintf("%T", ncError.RootError), Value: rootMessage})
+	}
+
+	for i := len(ncError.Causes) - 1; i >= 0; i-- {
+		cause := ncError.Causes[i]
+		// The root error is recorded as the cause without the location.
+		if ncError.RootError != nil && cause.FuncName == "" && cause.Message == rootMessage {
+			continue
+		}
+
+		exception := sentry.Exception{Type: ncErrorType, Value: cause.Message}
+		if cause.Code != "" {
+			exception.Type = cause.Code
+		}
+		result = append(result, exception)
+	}
+
+	if len(result) == 0 {
+		result = append(result, sentry.Exception{Type: ncErrorType, Value: ncError.Error()})
+	}
+	return result
+}
+
+// stacktrace converts the raw stack of the error into sentry.Stacktrace with the frames ordered from the outermost
+// one, as expected by Sentry.
+func (r *Reporter) stacktrace(ncError errors.NCError) *sentry.Stacktrace {
+	if ncError.RawStack == nil {
+		return nil
+	}
+
+	rawStack := ncError.StackTrace()
+	pcs := make([]uintptr, len(rawStack))
+	for i, f := range rawStack {
+		pcs[i] = uintptr(f)
+	}
+
+	var frames []sentry.Frame
+	callFrames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := callFrames.Next()
+		if frame.Function != "" {
+			sentryFrame := sentry.NewFrame(frame)
+			sentryFrame.InApp = errors.IsInAppFrame(frame, r.inAppPrefixes...)
+			frames = append([]sentry.Frame{sentryFrame}, frames...)
+		}
+		if !more {
+			break
+		}
+	}
+
+	if len(frames) == 0 {
+		return nil
+	}
+	return &sentry.Stacktrace{Frames: frames}
+}
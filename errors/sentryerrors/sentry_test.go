@@ -0,0 +1,107 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package sentryerrors
+
+import (
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/getsentry/sentry-go"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTransport struct {
+	mu     sync.Mutex
+	events []*sentry.Event
+}
+
+func (t *fakeTransport) Configure(sentry.ClientOptions) {}
+func (t *fakeTransport) Flush(time.Duration) bool       { return true }
+func (t *fakeTransport) SendEvent(event *sentry.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event)
+}
+
+func newTestReporter(t *testing.T, opts ...Option) (*Reporter, *fakeTransport) {
+	transport := &fakeTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	assert.NoError(t, err)
+	return NewReporter(sentry.NewHub(client, sentry.NewScope()), opts...), transport
+}
+
+func TestReporter_NCError(t *testing.T) {
+	reporter, transport := newTestReporter(t, WithInAppPrefixes("github.com/nordcloud/ncerrors/"))
+
+	awsErr := awserr.New("Throttling", "rate exceeded", nil)
+	err := errors.WithContext(awsErr, "describe instances", errors.Fields{"region": "eu-west-1", "token": "t0ken"})
+	err = errors.WithCode(errors.Wrap(err, "list instances"), "LIST_FAILED")
+	reporter.Report(err)
+
+	assert.Len(t, transport.events, 1)
+	event := transport.events[0]
+	assert.Equal(t, sentry.LevelError, event.Level)
+	assert.Equal(t, err.Error(), event.Message)
+	assert.Equal(t, map[string]string{
+		TagCode:         "LIST_FAILED",
+		TagSeverity:     "error",
+		TagAWSErrorCode: "Throttling",
+	}, event.Tags)
+	assert.Equal(t, "eu-west-1", event.Extra["region"])
+	assert.Equal(t, errors.RedactedValue, event.Extra["token"])
+	assert.Equal(t, []string{errors.Fingerprint(err)}, event.Fingerprint)
+
+	assert.Len(t, event.Exception, 3)
+	assert.Equal(t, "*awserr.baseError", event.Exception[0].Type)
+	assert.Equal(t, awsErr.Error(), event.Exception[0].Value)
+	assert.Equal(t, sentry.Exception{Type: "NCError", Value: "describe instances"}, event.Exception[1])
+	assert.Equal(t, "LIST_FAILED", event.Exception[2].Type)
+	assert.Equal(t, "list instances", event.Exception[2].Value)
+
+	// The frames are ordered from the outermost one and start with the constructor of the error.
+	frames := event.Exception[2].Stacktrace.Frames
+	assert.GreaterOrEqual(t, len(frames), 3)
+	assert.Equal(t, "WithContext", frames[len(frames)-1].Function)
+	assert.False(t, frames[len(frames)-1].InApp)
+	assert.Equal(t, "TestReporter_NCError", frames[len(frames)-2].Function)
+	assert.True(t, frames[len(frames)-2].InApp)
+	assert.False(t, frames[0].InApp)
+}
+
+func TestReporter_StandardError(t *testing.T) {
+	reporter, transport := newTestReporter(t)
+
+	reporter.Report(stderrors.New("std"))
+	reporter.Report(nil)
+
+	assert.Len(t, transport.events, 1)
+	event := transport.events[0]
+	assert.Equal(t, []sentry.Exception{{Type: "*errors.errorString", Value: "std"}}, event.Exception)
+	assert.Equal(t, map[string]string{TagSeverity: "error"}, event.Tags)
+}
+
+func TestLogWithSeverity(t *testing.T) {
+	reporter, transport := newTestReporter(t)
+	errors.SetReporter(reporter)
+	defer errors.SetReporter(nil)
+
+	errors.LogWithSeverity(errors.NewWithSeverity("warning", nil, errors.WARN))
+	errors.LogWithSeverity(errors.New("error"))
+
+	assert.Len(t, transport.events, 1)
+	event := transport.events[0]
+	assert.Equal(t, "error", event.Message)
+	assert.Len(t, event.Exception, 1)
+	assert.NotNil(t, event.Exception[0].Stacktrace)
+}
+
+func TestLevel(t *testing.T) {
+	assert.Equal(t, sentry.LevelWarning, Level(errors.WARN))
+	assert.Equal(t, sentry.LevelInfo, Level(errors.INFO))
+	assert.Equal(t, sentry.LevelDebug, Level(errors.DEBUG))
+	assert.Equal(t, sentry.LevelError, Level("unknown"))
+}
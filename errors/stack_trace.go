@@ -18,8 +18,20 @@ var (
 // getStackTraces returns custom-formatted and raw (in the form of program counters) stack trace
 // for the purpose of initializing NCError struct
 func getStackTraces() ([]string, *stack) {
+	return formatStackTraces(callers(0))
+}
+
+// getStackTracesSkip is getStackTraces skipping additional frames of the wrapper functions.
+func getStackTracesSkip(skip int) ([]string, *stack) {
+	return formatStackTraces(callers(skip))
+}
+
+func formatStackTraces(rawStack *stack) ([]string, *stack) {
 	var formattedStack []string
-	callStack := *callers()
+	callStack := *rawStack
+	if len(callStack) == 0 {
+		return formattedStack, rawStack
+	}
 	st := callStack[:len(callStack)-1]
 	for _, f := range st {
 		frame := frame(f)
@@ -76,12 +88,23 @@ func (f frame) formatContext() string {
 
 // GetRuntimeContext returns function name and code line.
 func GetRuntimeContext() (fileName, funcName string, line int) {
-	st := *callers()
+	st := *callers(0)
 	frame := frame(st[1])
 	fileName, funcName, line = frame.getContext()
 	return
 }
 
+// getRuntimeContextSkip is GetRuntimeContext skipping additional frames of the wrapper functions.
+func getRuntimeContextSkip(skip int) (fileName, funcName string, line int) {
+	st := *callers(0)
+	if len(st) <= 1+skip {
+		return "", "", 0
+	}
+	frame := frame(st[1+skip])
+	fileName, funcName, line = frame.getContext()
+	return
+}
+
 type stack []uintptr
 
 func (s *stack) StackTrace() errors.StackTrace {
@@ -92,10 +115,10 @@ func (s *stack) StackTrace() errors.StackTrace {
 	return f
 }
 
-func callers() *stack {
+func callers(skip int) *stack {
 	const depth = 32
 	var pcs [depth]uintptr
-	n := runtime.Callers(3, pcs[:])
+	n := runtime.Callers(3+skip, pcs[:])
 	var st stack = pcs[0:n]
 	return &st
 }
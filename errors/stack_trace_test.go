@@ -3,6 +3,7 @@
 package errors
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,6 +32,18 @@ func (ts testStruct) nested() error {
 	return fn()
 }
 
+var frameLineRegex = regexp.MustCompile(`:\d+$`)
+
+// frameNames strips the line numbers from the formatted stack frames, so the expectations don't depend on the code
+// layout.
+func frameNames(stack []string) []string {
+	names := make([]string, len(stack))
+	for i, f := range stack {
+		names[i] = frameLineRegex.ReplaceAllString(f, "")
+	}
+	return names
+}
+
 func TestSimpleFuncStack(t *testing.T) {
 	for _, tc := range []struct {
 		fn    func() error
@@ -39,34 +52,34 @@ func TestSimpleFuncStack(t *testing.T) {
 		{
 			func() error { return innerFunc() },
 			[]string{
-				"github.com/nordcloud/ncerrors/errors/error.go(New):135",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):12",
+				"github.com/nordcloud/ncerrors/errors/error.go(New):114",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):13",
 			},
 		},
 		{
 			func() error { return outerFunc() },
 			[]string{
-				"github.com/nordcloud/ncerrors/errors/error.go(New):135",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):12",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(outerFunc):16",
+				"github.com/nordcloud/ncerrors/errors/error.go(New):114",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):13",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(outerFunc):17",
 			},
 		},
 		{
 			func() error { return testStruct{outerFunc}.method() },
 			[]string{
-				"github.com/nordcloud/ncerrors/errors/error.go(New):135",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):12",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(outerFunc):16",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(testStruct.method):24",
+				"github.com/nordcloud/ncerrors/errors/error.go(New):114",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):13",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(outerFunc):17",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(testStruct.method):25",
 			},
 		},
 		{
 			func() error { return testStruct{innerFunc}.nested() },
 			[]string{
-				"github.com/nordcloud/ncerrors/errors/error.go(New):135",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):12",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(testStruct.nested.func1):29",
-				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(testStruct.nested):31",
+				"github.com/nordcloud/ncerrors/errors/error.go(New):114",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(innerFunc):13",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(testStruct.nested.func1):30",
+				"github.com/nordcloud/ncerrors/errors/stack_trace_test.go(testStruct.nested):32",
 			},
 		},
 	} {
@@ -80,11 +93,11 @@ func TestGetSingleTrace(t *testing.T) {
 	s := GetTrace()
 	// Returns list of the stack trace
 	assert.Len(t, s, 2)
-	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/stack_trace_test.go(TestGetSingleTrace):80", s[0])
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/stack_trace_test.go(TestGetSingleTrace):93", s[0])
 }
 
 func TestGetCallStackTrace(t *testing.T) {
 	s := GetTrace()
 	assert.Len(t, s, 2)
-	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/stack_trace_test.go(TestGetCallStackTrace):87", s[0])
+	assert.Equal(t, "github.com/nordcloud/ncerrors/errors/stack_trace_test.go(TestGetCallStackTrace):100", s[0])
 }
@@ -9,4 +9,5 @@ var (
 	Unwrap = errors.Unwrap
 	Is     = errors.Is
 	As     = errors.As
+	Join   = errors.Join
 )
@@ -0,0 +1,116 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package xrayerrors provides AWS X-Ray helpers for NCError.
+package xrayerrors
+
+import (
+	"context"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/nordcloud/ncerrors/errors"
+)
+
+// MetadataNamespace is the X-Ray metadata namespace of the error details.
+const MetadataNamespace = "error"
+
+// MetadataErrors is the metadata key of the list of the recorded errors' details. Each error is recorded as a map
+// with the keys below.
+const MetadataErrors = "errors"
+
+// Keys of the recorded error details.
+const (
+	MetadataExceptionID  = "exception_id"
+	MetadataFields       = "fields"
+	MetadataCode         = "code"
+	MetadataSeverity     = "severity"
+	MetadataAWSErrorCode = "aws_error_code"
+	MetadataFingerprint  = "fingerprint"
+)
+
+// RecordError adds the error to the current X-Ray segment or subsegment of the context. The exception is recorded
+// with the NCError stack trace and the redacted message. The segment is marked as:
+//   - throttle (and error) if the error is a throttling AWS error or of errors.KindRateLimited kind,
+//   - fault if the error severity is errors.ERROR,
+//   - error otherwise.
+//
+// The ID of the exception, the merged fields, code, severity, AWS error code and fingerprint are appended to the
+// MetadataErrors list under MetadataNamespace, so every recorded error is kept. The fields are redacted and bounded
+// the same way as in the logs. It returns false if there is no segment in the context or the error is nil.
+func RecordError(ctx context.Context, err error) bool {
+	if err == nil || xray.SdkDisabled() {
+		return false
+	}
+	seg := xray.GetSegment(ctx)
+	if seg == nil {
+		return false
+	}
+
+	details := errors.GetLogDetails(err)
+	throttle := isThrottle(err, details)
+	fault := !throttle && details.Severity == errors.ERROR
+	var exceptionErr error = err
+	if details.NCError != nil {
+		exceptionErr = details.NCError
+	}
+
+	// The segment is updated in one critical section, so the flags of the concurrently recorded errors are not lost.
+	seg.Lock()
+	defer seg.Unlock()
+
+	exception := seg.ParentSegment.GetConfiguration().ExceptionFormattingStrategy.ExceptionFromError(exceptionErr)
+	cause := seg.GetCause()
+	cause.WorkingDirectory, _ = os.Getwd()
+	cause.Exceptions = append(cause.Exceptions, exception)
+	seg.Fault = seg.Fault || fault
+	seg.Error = seg.Error || !fault
+	seg.Throttle = seg.Throttle || throttle
+
+	if seg.Dummy {
+		return true
+	}
+	if seg.Metadata == nil {
+		seg.Metadata = map[string]map[string]interface{}{}
+	}
+	if seg.Metadata[MetadataNamespace] == nil {
+		seg.Metadata[MetadataNamespace] = map[string]interface{}{}
+	}
+	recorded, _ := seg.Metadata[MetadataNamespace][MetadataErrors].([]map[string]interface{})
+	seg.Metadata[MetadataNamespace][MetadataErrors] = append(recorded, errorMetadata(err, details, exception.ID))
+	return true
+}
+
+// IsThrottle tells if the error is a throttling AWS error or of errors.KindRateLimited kind.
+func IsThrottle(err error) bool {
+	return isThrottle(err, errors.GetLogDetails(err))
+}
+
+func isThrottle(err error, details errors.LogDetails) bool {
+	if errors.GetKind(err) == errors.KindRateLimited {
+		return true
+	}
+	return details.AWSError != nil && request.IsErrorThrottle(details.AWSError)
+}
+
+func errorMetadata(err error, details errors.LogDetails, exceptionID string) map[string]interface{} {
+	metadata := map[string]interface{}{
+		MetadataExceptionID: exceptionID,
+		MetadataSeverity:    string(details.Severity),
+	}
+	if details.Fingerprint != "" {
+		metadata[MetadataFingerprint] = details.Fingerprint
+	}
+	if code := errors.GetCode(err); code != "" {
+		metadata[MetadataCode] = code
+	}
+	if details.AWSError != nil {
+		metadata[MetadataAWSErrorCode] = details.AWSError.Code()
+	}
+	if details.NCError != nil {
+		if fields := details.NCError.GetMergedFields(); len(fields) > 0 {
+			metadata[MetadataFields] = fields
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,148 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package xrayerrors
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-xray-sdk-go/strategy/sampling"
+	"github.com/aws/aws-xray-sdk-go/xray"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEmitter collects the emitted segments instead of sending them to the daemon.
+type testEmitter struct {
+	mu       sync.Mutex
+	segments []*xray.Segment
+}
+
+func (e *testEmitter) Emit(seg *xray.Segment) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.segments = append(e.segments, seg)
+}
+
+func (e *testEmitter) RefreshEmitterWithAddress(*net.UDPAddr) {}
+
+func newTestSegment(t *testing.T) (context.Context, *xray.Segment, *testEmitter) {
+	emitter := &testEmitter{}
+	strategy, err := sampling.NewLocalizedStrategyFromJSONBytes([]byte(`{"version": 2, "default": {"fixed_target": 100, "rate": 1}}`))
+	assert.NoError(t, err)
+	ctx, err := xray.ContextWithConfig(context.Background(), xray.Config{Emitter: emitter, SamplingStrategy: strategy})
+	assert.NoError(t, err)
+	ctx, seg := xray.BeginSegment(ctx, "test")
+	return ctx, seg, emitter
+}
+
+func TestRecordError_Fault(t *testing.T) {
+	ctx, seg, emitter := newTestSegment(t)
+
+	err := errors.WithCode(errors.New("error", errors.Fields{"id": 1, "token": "t0ken"}), "CODE")
+	assert.True(t, RecordError(ctx, err))
+	seg.Close(nil)
+
+	assert.Len(t, emitter.segments, 1)
+	assert.True(t, seg.Fault)
+	assert.False(t, seg.Error)
+	assert.False(t, seg.Throttle)
+	exceptions := seg.Cause.Exceptions
+	assert.Len(t, exceptions, 1)
+	assert.Equal(t, "error", exceptions[0].Message)
+	assert.NotEmpty(t, exceptions[0].Stack)
+	assert.Equal(t, "TestRecordError_Fault", exceptions[0].Stack[1].Label)
+	assert.Equal(t, []map[string]interface{}{{
+		MetadataExceptionID: exceptions[0].ID,
+		MetadataSeverity:    "error",
+		MetadataCode:        "CODE",
+		MetadataFingerprint: errors.Fingerprint(err),
+		MetadataFields:      errors.Fields{"id": 1, "token": errors.RedactedValue},
+	}}, seg.Metadata[MetadataNamespace][MetadataErrors])
+}
+
+func TestRecordError_ErrorAndThrottle(t *testing.T) {
+	ctx, seg, _ := newTestSegment(t)
+	subCtx, subseg := xray.BeginSubsegment(ctx, "sub")
+
+	assert.True(t, RecordError(subCtx, errors.NewWithSeverity("invalid", nil, errors.WARN)))
+	assert.False(t, subseg.Fault)
+	assert.True(t, subseg.Error)
+	assert.False(t, subseg.Throttle)
+
+	awsErr := awserr.New("ThrottlingException", "rate exceeded", nil)
+	assert.True(t, RecordError(subCtx, errors.WithContext(awsErr, "call")))
+	assert.False(t, subseg.Fault)
+	assert.True(t, subseg.Error)
+	assert.True(t, subseg.Throttle)
+	recorded := subseg.Metadata[MetadataNamespace][MetadataErrors].([]map[string]interface{})
+	assert.Len(t, recorded, 2)
+	assert.Equal(t, "warning", recorded[0][MetadataSeverity])
+	assert.Equal(t, "ThrottlingException", recorded[1][MetadataAWSErrorCode])
+	assert.Len(t, subseg.Cause.Exceptions, 2)
+
+	// The fault is kept.
+	assert.True(t, RecordError(subCtx, stderrors.New("std")))
+	assert.True(t, RecordError(subCtx, errors.WithKind(errors.New("limited"), errors.KindRateLimited)))
+	assert.True(t, subseg.Fault)
+	subseg.Close(nil)
+	seg.Close(nil)
+
+	assert.False(t, seg.Fault)
+}
+
+func TestRecordError_NoSegment(t *testing.T) {
+	assert.False(t, RecordError(context.Background(), errors.New("error")))
+
+	ctx, seg, _ := newTestSegment(t)
+	assert.False(t, RecordError(ctx, nil))
+	seg.Close(nil)
+}
+
+func TestIsThrottle(t *testing.T) {
+	assert.True(t, IsThrottle(awserr.New("Throttling", "rate exceeded", nil)))
+	assert.True(t, IsThrottle(errors.WithKind(errors.New("limited"), errors.KindRateLimited)))
+	assert.False(t, IsThrottle(errors.WithContext(awserr.New("AccessDenied", "denied", nil), "call")))
+	assert.False(t, IsThrottle(stderrors.New("std")))
+}
+
+func TestRecordError_WrappedNCError(t *testing.T) {
+	ctx, seg, _ := newTestSegment(t)
+
+	err := fmt.Errorf("handler: %w", errors.New("user john@example.com not found"))
+	assert.True(t, RecordError(ctx, err))
+	seg.Close(nil)
+
+	exception := seg.Cause.Exceptions[0]
+	assert.Equal(t, "user [REDACTED] not found", exception.Message)
+	assert.Equal(t, "TestRecordError_WrappedNCError", exception.Stack[1].Label)
+}
+
+func TestRecordError_Concurrent(t *testing.T) {
+	ctx, seg, _ := newTestSegment(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			severity := errors.WARN
+			if i == 0 {
+				severity = errors.ERROR
+			}
+			RecordError(ctx, errors.NewWithSeverity("error", nil, severity))
+		}(i)
+	}
+	wg.Wait()
+	seg.Close(nil)
+
+	assert.True(t, seg.Fault)
+	assert.True(t, seg.Error)
+	assert.Len(t, seg.Cause.Exceptions, 10)
+	assert.Len(t, seg.Metadata[MetadataNamespace][MetadataErrors], 10)
+}
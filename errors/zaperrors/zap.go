@@ -0,0 +1,147 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package zaperrors provides go.uber.org/zap adapter for NCError.
+package zaperrors
+
+import (
+	"sort"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ErrorKey is the key of the field added by Field.
+const ErrorKey = "error"
+
+// Error implements zapcore.ObjectMarshaler for the error. NCError is marshalled with its causes, merged fields, stack,
+// severity and AWS error code, other errors with message (and AWS error code) only.
+type Error struct {
+	Err error
+}
+
+// Field returns zap.Field with the error marshalled as an object under ErrorKey.
+func Field(err error) zap.Field {
+	return zap.Object(ErrorKey, Error{Err: err})
+}
+
+// MarshalLogObject marshals the error to the zap object encoder.
+func (e Error) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	details := errors.GetLogDetails(e.Err)
+	enc.AddString("message", details.Message)
+	if details.Severity != "" {
+		enc.AddString("severity", string(details.Severity))
+	}
+	if details.AWSError != nil {
+		enc.AddString("aws_error_code", details.AWSError.Code())
+		enc.AddString("aws_error_message", errors.RedactMessage(details.AWSError.Message()))
+	}
+	if details.DocsURL != "" {
+		enc.AddString("docs_url", details.DocsURL)
+	}
+	if details.Owner != "" {
+		enc.AddString("owner", details.Owner)
+	}
+	if details.Fingerprint != "" {
+		enc.AddString("fingerprint", details.Fingerprint)
+	}
+	if details.NCError == nil {
+		return nil
+	}
+
+	if err := enc.AddObject("fields", fields(details.NCError.GetMergedFields())); err != nil {
+		return err
+	}
+	if err := enc.AddArray("causes", causes(details.NCError.Causes)); err != nil {
+		return err
+	}
+	return enc.AddArray("stack", zapcore.ArrayMarshalerFunc(func(arr zapcore.ArrayEncoder) error {
+		for _, frame := range details.NCError.Stack {
+			arr.AppendString(frame)
+		}
+		return nil
+	}))
+}
+
+// LogWithSeverity uses severity stored in the error to select appropriate log level. The log observers are notified
+// (see errors.AddLogObserver) and errors of ERROR severity are reported (see errors.Report) unless suppressed by the
+// configured RateLimiter (see errors.SetRateLimiter).
+func LogWithSeverity(logger *zap.Logger, err error) {
+	severity := errors.GetErrorSeverity(err)
+	if Level(severity) == zapcore.ErrorLevel {
+		severity = errors.ERROR
+	}
+	errors.NotifyLogObservers(err, severity)
+	if !errors.AllowLog(err, severity, summary(logger)) {
+		return
+	}
+	logger.Log(Level(severity), errors.RedactMessage(err.Error()), Field(err))
+	if severity == errors.ERROR {
+		errors.Report(err)
+	}
+}
+
+// summary returns errors.SummaryFunc logging the summary of the suppressed occurrences with the logger.
+func summary(logger *zap.Logger) errors.SummaryFunc {
+	return func(err error, severity errors.LogSeverity, suppressed int, message string) {
+		logger.Log(Level(severity), message, Field(err), zap.Int(errors.SuppressedKey, suppressed))
+	}
+}
+
+// Level converts LogSeverity into zapcore.Level. Unknown severities are mapped to zapcore.ErrorLevel.
+func Level(severity errors.LogSeverity) zapcore.Level {
+	switch severity {
+	case errors.WARN:
+		return zapcore.WarnLevel
+	case errors.INFO:
+		return zapcore.InfoLevel
+	case errors.DEBUG:
+		return zapcore.DebugLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+type fields errors.Fields
+
+func (f fields) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if err := enc.AddReflected(k, f[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type causes []errors.Cause
+
+func (c causes) MarshalLogArray(arr zapcore.ArrayEncoder) error {
+	for _, cause := range c {
+		cause := cause
+		err := arr.AppendObject(zapcore.ObjectMarshalerFunc(func(enc zapcore.ObjectEncoder) error {
+			enc.AddString("message", cause.Message)
+			if cause.FuncName != "" {
+				enc.AddString("func", cause.FuncName)
+				enc.AddString("file", cause.FileName)
+				enc.AddInt("line", cause.Line)
+			}
+			if cause.Severity != "" {
+				enc.AddString("severity", string(cause.Severity))
+			}
+			if len(cause.Fields) > 0 {
+				return enc.AddObject("fields", fields(cause.Fields))
+			}
+			return nil
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package zaperrors
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLogWithSeverity_NCError(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	awsErr := awserr.New("code", "aws message", nil)
+	err := errors.WithContextAndSeverity(awsErr, "level1", errors.WARN, errors.Fields{"field1": "val1"})
+	LogWithSeverity(logger, err)
+
+	assert.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.WarnLevel, entry.Level)
+	assert.Equal(t, err.Error(), entry.Message)
+
+	errCtx := entry.ContextMap()[ErrorKey].(map[string]interface{})
+	assert.Equal(t, err.Error(), errCtx["message"])
+	assert.Equal(t, "warning", errCtx["severity"])
+	assert.Equal(t, "code", errCtx["aws_error_code"])
+	assert.Equal(t, errors.Fingerprint(err), errCtx["fingerprint"])
+	assert.Equal(t, map[string]interface{}{"field1": "val1"}, errCtx["fields"])
+	assert.Len(t, errCtx["causes"], 2)
+	assert.Equal(t, map[string]interface{}{
+		"message":  "level1",
+		"func":     "TestLogWithSeverity_NCError",
+		"file":     "github.com/nordcloud/ncerrors/errors/zaperrors/zap_test.go",
+		"line":     23,
+		"severity": "warning",
+		"fields":   map[string]interface{}{"field1": "val1"},
+	}, errCtx["causes"].([]interface{})[0])
+	assert.NotEmpty(t, errCtx["stack"])
+}
+
+func TestLogWithSeverity_StandardError(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	LogWithSeverity(logger, stderrors.New("std"))
+
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.ErrorLevel, entry.Level)
+	assert.Equal(t, map[string]interface{}{"message": "std", "severity": "error"}, entry.ContextMap()[ErrorKey])
+}
+
+type testReporter struct {
+	reported []error
+}
+
+func (r *testReporter) Report(err error) {
+	r.reported = append(r.reported, err)
+}
+
+func TestLogWithSeverity_RateLimited(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+	errors.SetRateLimiter(errors.NewRateLimiter(errors.RateLimit{Burst: 1, Window: time.Minute}))
+	defer errors.SetRateLimiter(nil)
+	reporter := &testReporter{}
+	errors.SetReporter(reporter)
+	defer errors.SetReporter(nil)
+
+	for i := 0; i < 3; i++ {
+		LogWithSeverity(logger, errors.New("error", errors.Fields{"id": i}))
+	}
+	assert.Equal(t, 1, logs.Len())
+	assert.Len(t, reporter.reported, 1)
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+// Package zerologerrors provides github.com/rs/zerolog adapter for NCError.
+package zerologerrors
+
+import (
+	"sort"
+
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/rs/zerolog"
+)
+
+// ErrorKey is the key of the object added by LogWithSeverity.
+const ErrorKey = "error"
+
+// Error implements zerolog.LogObjectMarshaler for the error. NCError is marshalled with its causes, merged fields,
+// stack, severity and AWS error code, other errors with message (and AWS error code) only.
+type Error struct {
+	Err error
+}
+
+// MarshalZerologObject marshals the error to the zerolog event.
+func (e Error) MarshalZerologObject(ev *zerolog.Event) {
+	details := errors.GetLogDetails(e.Err)
+	ev.Str("message", details.Message)
+	if details.Severity != "" {
+		ev.Str("severity", string(details.Severity))
+	}
+	if details.AWSError != nil {
+		ev.Str("aws_error_code", details.AWSError.Code())
+		ev.Str("aws_error_message", errors.RedactMessage(details.AWSError.Message()))
+	}
+	if details.DocsURL != "" {
+		ev.Str("docs_url", details.DocsURL)
+	}
+	if details.Owner != "" {
+		ev.Str("owner", details.Owner)
+	}
+	if details.Fingerprint != "" {
+		ev.Str("fingerprint", details.Fingerprint)
+	}
+	if details.NCError == nil {
+		return
+	}
+
+	ev.Object("fields", fields(details.NCError.GetMergedFields()))
+	ev.Array("causes", causes(details.NCError.Causes))
+	ev.Strs("stack", details.NCError.Stack)
+}
+
+// LogWithSeverity uses severity stored in the error to select appropriate log level. The log observers are notified
+// (see errors.AddLogObserver) and errors of ERROR severity are reported (see errors.Report) unless suppressed by the
+// configured RateLimiter (see errors.SetRateLimiter).
+func LogWithSeverity(logger *zerolog.Logger, err error) {
+	severity := errors.GetErrorSeverity(err)
+	if Level(severity) == zerolog.ErrorLevel {
+		severity = errors.ERROR
+	}
+	errors.NotifyLogObservers(err, severity)
+	if !errors.AllowLog(err, severity, summary(logger)) {
+		return
+	}
+	logger.WithLevel(Level(severity)).Object(ErrorKey, Error{Err: err}).Msg(errors.RedactMessage(err.Error()))
+	if severity == errors.ERROR {
+		errors.Report(err)
+	}
+}
+
+// summary returns errors.SummaryFunc logging the summary of the suppressed occurrences with the logger.
+func summary(logger *zerolog.Logger) errors.SummaryFunc {
+	return func(err error, severity errors.LogSeverity, suppressed int, message string) {
+		logger.WithLevel(Level(severity)).Object(ErrorKey, Error{Err: err}).Int(errors.SuppressedKey, suppressed).
+			Msg(message)
+	}
+}
+
+// Level converts LogSeverity into zerolog.Level. Unknown severities are mapped to zerolog.ErrorLevel.
+func Level(severity errors.LogSeverity) zerolog.Level {
+	switch severity {
+	case errors.WARN:
+		return zerolog.WarnLevel
+	case errors.INFO:
+		return zerolog.InfoLevel
+	case errors.DEBUG:
+		return zerolog.DebugLevel
+	default:
+		return zerolog.ErrorLevel
+	}
+}
+
+type fields errors.Fields
+
+func (f fields) MarshalZerologObject(ev *zerolog.Event) {
+	keys := make([]string, 0, len(f))
+	for k := range f {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		ev.Interface(k, f[k])
+	}
+}
+
+type cause errors.Cause
+
+func (c cause) MarshalZerologObject(ev *zerolog.Event) {
+	ev.Str("message", c.Message)
+	if c.FuncName != "" {
+		ev.Str("func", c.FuncName)
+		ev.Str("file", c.FileName)
+		ev.Int("line", c.Line)
+	}
+	if c.Severity != "" {
+		ev.Str("severity", string(c.Severity))
+	}
+	if len(c.Fields) > 0 {
+		ev.Object("fields", fields(c.Fields))
+	}
+}
+
+type causes []errors.Cause
+
+func (c causes) MarshalZerologArray(arr *zerolog.Array) {
+	for _, v := range c {
+		arr.Object(cause(v))
+	}
+}
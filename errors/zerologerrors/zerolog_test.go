@@ -0,0 +1,85 @@
+// Copyright 2023 Nordcloud Oy or its affiliates. All Rights Reserved.
+
+package zerologerrors
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/nordcloud/ncerrors/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogWithSeverity_NCError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	awsErr := awserr.New("code", "aws message", nil)
+	err := errors.WithContextAndSeverity(awsErr, "level1", errors.DEBUG, errors.Fields{"field1": "val1"})
+	LogWithSeverity(&logger, err)
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "debug", entry[zerolog.LevelFieldName])
+	assert.Equal(t, err.Error(), entry[zerolog.MessageFieldName])
+
+	errCtx := entry[ErrorKey].(map[string]interface{})
+	assert.Equal(t, err.Error(), errCtx["message"])
+	assert.Equal(t, "debug", errCtx["severity"])
+	assert.Equal(t, "code", errCtx["aws_error_code"])
+	assert.Equal(t, errors.Fingerprint(err), errCtx["fingerprint"])
+	assert.Equal(t, map[string]interface{}{"field1": "val1"}, errCtx["fields"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{
+			"message":  "level1",
+			"func":     "TestLogWithSeverity_NCError",
+			"file":     "github.com/nordcloud/ncerrors/errors/zerologerrors/zerolog_test.go",
+			"line":     float64(23),
+			"severity": "debug",
+			"fields":   map[string]interface{}{"field1": "val1"},
+		},
+		map[string]interface{}{"message": "code: aws message"},
+	}, errCtx["causes"])
+	assert.NotEmpty(t, errCtx["stack"])
+}
+
+func TestLogWithSeverity_StandardError(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	LogWithSeverity(&logger, stderrors.New("std"))
+
+	var entry map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "error", entry[zerolog.LevelFieldName])
+	assert.Equal(t, map[string]interface{}{"message": "std", "severity": "error"}, entry[ErrorKey])
+}
+
+type testReporter struct {
+	reported []error
+}
+
+func (r *testReporter) Report(err error) {
+	r.reported = append(r.reported, err)
+}
+
+func TestLogWithSeverity_RateLimited(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	errors.SetRateLimiter(errors.NewRateLimiter(errors.RateLimit{Burst: 1, Window: time.Minute}))
+	defer errors.SetRateLimiter(nil)
+	reporter := &testReporter{}
+	errors.SetReporter(reporter)
+	defer errors.SetReporter(nil)
+
+	for i := 0; i < 3; i++ {
+		LogWithSeverity(&logger, errors.New("error", errors.Fields{"id": i}))
+	}
+	assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")))
+	assert.Len(t, reporter.reported, 1)
+}